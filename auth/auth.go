@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUnauthenticated is returned when a token is missing or invalid
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Identity describes an authenticated caller
+type Identity struct {
+	Name string
+	Role string
+}
+
+type identityKey struct{}
+
+// NewContext returns a context carrying the identity
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity of the caller if there is one
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// Authenticator checks bearer tokens: static API keys first, then JWTs
+type Authenticator struct {
+	keys   map[string]*Identity
+	secret []byte
+}
+
+// NewAuthenticator creates an authenticator from API keys and a JWT secret.
+// Either of them may be empty
+func NewAuthenticator(keys map[string]*Identity, jwtSecret []byte) *Authenticator {
+	return &Authenticator{
+		keys:   keys,
+		secret: jwtSecret,
+	}
+}
+
+// Authenticate returns an identity of the token owner
+func (a *Authenticator) Authenticate(token string) (*Identity, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: token is missing", ErrUnauthenticated)
+	}
+
+	if id, ok := a.keys[token]; ok {
+		return id, nil
+	}
+
+	if len(a.secret) == 0 {
+		return nil, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+	}
+
+	claims, err := VerifyJWT(token, a.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{Name: claims.Subject, Role: claims.Role}, nil
+}
+
+// LoadKeys reads API keys from a file.
+// Every line contains a key, a role and an optional name separated by spaces.
+// Empty lines and lines starting with # are skipped
+func LoadKeys(file string) (map[string]*Identity, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := make(map[string]*Identity)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: a key and a role are required", file, line)
+		}
+
+		id := &Identity{Role: fields[1]}
+		if len(fields) > 2 {
+			id.Name = fields[2]
+		} else {
+			id.Name = "key:" + keyPrefix(fields[0])
+		}
+
+		keys[fields[0]] = id
+	}
+
+	return keys, scanner.Err()
+}
+
+// keyPrefix returns a short prefix of a key, which is safe to show in logs
+func keyPrefix(key string) string {
+	if len(key) > 6 {
+		return key[:6]
+	}
+
+	return key
+}
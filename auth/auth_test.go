@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("secret")
+
+// signedJWT returns a token with the header and claims signed by the secret
+func signedJWT(t *testing.T, header jwtHeader, claims *Claims, secret []byte) string {
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := encodeSegment(h) + "." + encodeSegment(c)
+	return unsigned + "." + encodeSegment(sign(unsigned, secret))
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys")
+	data := "# keys\n\ns3cr3t game gameserver-1\nabcdefgh editor\n"
+	if err := os.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeys(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAuthenticator(keys, nil)
+
+	id, err := a.Authenticate("s3cr3t")
+	if err != nil || id.Name != "gameserver-1" || id.Role != "game" {
+		t.Errorf("Authenticate(s3cr3t) = %+v, %v, want gameserver-1 with game role", id, err)
+	}
+
+	id, err = a.Authenticate("abcdefgh")
+	if err != nil || id.Name != "key:abcdef" || id.Role != "editor" {
+		t.Errorf("Authenticate(abcdefgh) = %+v, %v, want key:abcdef with editor role", id, err)
+	}
+
+	for _, token := range []string{"", "unknown"} {
+		if _, err := a.Authenticate(token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Authenticate(%q) error = %v, want ErrUnauthenticated", token, err)
+		}
+	}
+
+	// Without a secret JWTs aren't accepted even if they are signed with an empty key
+	token, err := SignJWT(&Claims{Subject: "user", Role: "admin"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Authenticate(token); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Authenticate() of a JWT without a secret error = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestLoadKeysRequiresRole(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(file, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadKeys(file); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("LoadKeys() error = %v, want an error on line 1", err)
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	a := NewAuthenticator(map[string]*Identity{"s3cr3t": {Name: "key", Role: "game"}}, secret)
+
+	token, err := SignJWT(&Claims{Subject: "editor-1", Role: "editor", ExpiresAt: time.Now().Add(time.Hour).Unix()}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := a.Authenticate(token)
+	if err != nil || id.Name != "editor-1" || id.Role != "editor" {
+		t.Errorf("Authenticate() of a valid JWT = %+v, %v, want editor-1 with editor role", id, err)
+	}
+
+	if id, err := a.Authenticate("s3cr3t"); err != nil || id.Name != "key" {
+		t.Errorf("Authenticate(s3cr3t) with a secret = %+v, %v, want the API key", id, err)
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	now := time.Now()
+	hs256 := jwtHeader{Alg: "HS256", Typ: "JWT"}
+	valid := &Claims{Subject: "user", Role: "game"}
+
+	tests := []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{
+			name:  "expired",
+			token: signedJWT(t, hs256, &Claims{Subject: "user", Role: "game", ExpiresAt: now.Add(-time.Minute).Unix()}, secret),
+			err:   "token is expired",
+		},
+		{
+			name:  "not valid yet",
+			token: signedJWT(t, hs256, &Claims{Subject: "user", Role: "game", NotBefore: now.Add(time.Hour).Unix()}, secret),
+			err:   "token is not valid yet",
+		},
+		{
+			name:  "bad signature",
+			token: signedJWT(t, hs256, valid, []byte("another secret")),
+			err:   "invalid token signature",
+		},
+		{
+			name: "changed claims",
+			token: func() string {
+				parts := strings.Split(signedJWT(t, hs256, valid, secret), ".")
+				admin, _ := json.Marshal(&Claims{Subject: "user", Role: "admin"})
+				return parts[0] + "." + encodeSegment(admin) + "." + parts[2]
+			}(),
+			err: "invalid token signature",
+		},
+		{
+			name:  "none algorithm",
+			token: signedJWT(t, jwtHeader{Alg: "none"}, valid, secret),
+			err:   `unsupported token algorithm "none"`,
+		},
+		{
+			name:  "RS256 algorithm",
+			token: signedJWT(t, jwtHeader{Alg: "RS256", Typ: "JWT"}, valid, secret),
+			err:   `unsupported token algorithm "RS256"`,
+		},
+		{
+			name:  "no role",
+			token: signedJWT(t, hs256, &Claims{Subject: "user"}, secret),
+			err:   "token has no role",
+		},
+		{
+			name:  "malformed",
+			token: "a.b",
+			err:   "malformed token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := VerifyJWT(test.token, secret)
+			if !errors.Is(err, ErrUnauthenticated) || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("VerifyJWT() = %+v, %v, want %q", claims, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor authenticates bearer tokens of incoming calls
+// and authorizes them by the policy
+type Interceptor struct {
+	authenticator *Authenticator
+	policy        Policy
+	public        map[string]bool
+}
+
+// NewInterceptor creates an interceptor.
+// Full method names listed in public are called without a token
+func NewInterceptor(a *Authenticator, p Policy, public ...string) *Interceptor {
+	return &Interceptor{
+		authenticator: a,
+		policy:        p,
+		public:        methodSet(public),
+	}
+}
+
+// Unary returns a grpc unary server interceptor
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.check(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns a grpc stream server interceptor
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.check(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// check authenticates and authorizes a call, the returned context carries the identity
+func (i *Interceptor) check(ctx context.Context, fullMethod string) (context.Context, error) {
+	if i.public[fullMethod] {
+		return ctx, nil
+	}
+
+	id, err := i.authenticator.Authenticate(bearerToken(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if !i.policy.Allowed(id.Role, fullMethod) {
+		return nil, status.Errorf(codes.PermissionDenied, "Role %q may not call %s", id.Role, fullMethod)
+	}
+
+	return NewContext(ctx, id), nil
+}
+
+// bearerToken returns a token from the authorization metadata
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, value := range md.Get("authorization") {
+		if strings.HasPrefix(value, "Bearer ") {
+			return strings.TrimPrefix(value, "Bearer ")
+		}
+	}
+
+	return ""
+}
+
+// identityStream replaces a context of the server stream
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// TokenCredentials attaches a bearer token to every call of a client.
+// The token is sent only over TLS unless AllowInsecure is set
+type TokenCredentials struct {
+	Token         string
+	AllowInsecure bool
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (t TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.Token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials
+func (t TokenCredentials) RequireTransportSecurity() bool {
+	return !t.AllowInsecure
+}
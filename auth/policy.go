@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// anyMethod allows a role to call every method
+const anyMethod = "*"
+
+// defaultService is a grpc service of the method names given without one
+const defaultService = "question.Questions"
+
+// Policy maps roles to full names of the RPC methods they may call
+type Policy map[string]map[string]bool
+
+// DefaultPolicy lets game servers read questions, editors change them
+// and admins do anything
+func DefaultPolicy() Policy {
+	game := []string{"List", "Get", "Random", "Watch", "Search"}
+	editor := append([]string{"Put", "Update", "BulkPut", "Export", "Duplicates", "History", "Revert", "Trash", "Restore"}, game...)
+
+	return Policy{
+		"game":   methodSet(game),
+		"editor": methodSet(editor),
+		"admin":  methodSet([]string{anyMethod}),
+	}
+}
+
+// LoadPolicy reads a policy from a file.
+// Every line contains a role and method names separated by spaces, * means any method.
+// Full names like /grpc.health.v1.Health/Check name methods of other services,
+// short names belong to question.Questions.
+// Empty lines and lines starting with # are skipped
+func LoadPolicy(file string) (Policy, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	policy := make(Policy)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: a role and methods are required", file, line)
+		}
+
+		if policy[fields[0]] == nil {
+			policy[fields[0]] = make(map[string]bool)
+		}
+
+		for _, method := range fields[1:] {
+			policy[fields[0]][fullMethod(method)] = true
+		}
+	}
+
+	return policy, scanner.Err()
+}
+
+// Allowed reports whether the role may call the method.
+// fullMethod is a grpc method name like /question.Questions/List
+func (p Policy) Allowed(role, fullMethod string) bool {
+	methods := p[role]
+	return methods[anyMethod] || methods[fullMethod]
+}
+
+// fullMethod qualifies a short method name with the default service
+func fullMethod(method string) string {
+	if method == anyMethod || strings.HasPrefix(method, "/") {
+		return method
+	}
+
+	return "/" + defaultService + "/" + method
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		set[fullMethod(m)] = true
+	}
+
+	return set
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+
+	tests := []struct {
+		role, method string
+		allowed      bool
+	}{
+		{"game", "/question.Questions/List", true},
+		{"game", "/question.Questions/Put", false},
+		{"editor", "/question.Questions/Put", true},
+		{"editor", "/question.Questions/Purge", false},
+		{"admin", "/question.Questions/Purge", true},
+		{"unknown", "/question.Questions/List", false},
+		// Methods of other services with the same short name aren't allowed
+		{"game", "/other.Service/List", false},
+		{"game", "/grpc.reflection.v1alpha.ServerReflection/List", false},
+		{"game", "List", false},
+	}
+
+	for _, test := range tests {
+		if allowed := p.Allowed(test.role, test.method); allowed != test.allowed {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", test.role, test.method, allowed, test.allowed)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy")
+	data := "# roles\n\neditor List Put\nops /grpc.health.v1.Health/Check\nadmin *\n"
+	if err := os.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role, method string
+		allowed      bool
+	}{
+		{"editor", "/question.Questions/Put", true},
+		{"editor", "/other.Service/Put", false},
+		{"editor", "/question.Questions/Delete", false},
+		{"ops", "/grpc.health.v1.Health/Check", true},
+		{"ops", "/question.Questions/Check", false},
+		{"admin", "/other.Service/Anything", true},
+	}
+
+	for _, test := range tests {
+		if allowed := p.Allowed(test.role, test.method); allowed != test.allowed {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", test.role, test.method, allowed, test.allowed)
+		}
+	}
+}
+
+func TestTokenCredentials(t *testing.T) {
+	if !(TokenCredentials{Token: "t"}).RequireTransportSecurity() {
+		t.Errorf("TokenCredentials allow plain connections by default")
+	}
+
+	if (TokenCredentials{Token: "t", AllowInsecure: true}).RequireTransportSecurity() {
+		t.Errorf("TokenCredentials with AllowInsecure require TLS")
+	}
+}
@@ -0,0 +1,239 @@
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/almostmoore/gbquestion/metrics"
+	"github.com/almostmoore/gbquestion/question"
+)
+
+const (
+	// snapshotPrefix and snapshotSuffix surround the time in names of snapshot files
+	snapshotPrefix = "gbquestion-"
+	snapshotSuffix = ".db"
+
+	// snapshotTimeFormat is a UTC time of a snapshot in its file name
+	snapshotTimeFormat = "20060102-150405"
+)
+
+// Scheduler takes snapshots of a store into a directory periodically
+// and removes old ones. The newest snapshot of each of the last KeepHourly
+// calendar hours and of each of the last KeepDaily calendar days in UTC is kept
+type Scheduler struct {
+	backuper   question.Backuper
+	dir        string
+	interval   time.Duration
+	keepHourly int
+	keepDaily  int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler, it does nothing until it is started
+func NewScheduler(b question.Backuper, dir string, interval time.Duration, keepHourly, keepDaily int) *Scheduler {
+	return &Scheduler{
+		backuper:   b,
+		dir:        dir,
+		interval:   interval,
+		keepHourly: keepHourly,
+		keepDaily:  keepDaily,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start takes a snapshot every interval in background
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.run()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for a running snapshot
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// run takes a snapshot and rotates old ones, logging the outcome
+func (s *Scheduler) run() {
+	start := time.Now()
+
+	file, size, err := s.Snapshot(start)
+	metrics.ObserveBackup(start, size, err)
+	if err != nil {
+		log.Printf("Couldn't back up the database: %v", err)
+		return
+	}
+
+	log.Printf("Saved a snapshot of %d bytes to %s in %s", size, file, time.Since(start))
+
+	removed, err := s.Rotate(time.Now())
+	if err != nil {
+		log.Printf("Couldn't remove old snapshots: %v", err)
+	}
+
+	for _, name := range removed {
+		log.Printf("Removed old snapshot %s", name)
+	}
+}
+
+// Snapshot writes a snapshot taken at the given time into the directory
+// and returns its path and size. A partial snapshot never gets the final name
+func (s *Scheduler) Snapshot(at time.Time) (string, int64, error) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return "", 0, err
+	}
+
+	file := filepath.Join(s.dir, snapshotPrefix+at.UTC().Format(snapshotTimeFormat)+snapshotSuffix)
+
+	f, err := ioutil.TempFile(s.dir, ".partial-")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := s.backuper.Backup(f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return "", 0, err
+	}
+
+	if err := f.Close(); err != nil {
+		return "", 0, err
+	}
+
+	if err := os.Rename(f.Name(), file); err != nil {
+		return "", 0, err
+	}
+
+	return file, size, nil
+}
+
+// snapshot is a snapshot file found in the directory
+type snapshot struct {
+	name string
+	at   time.Time
+}
+
+// Rotate removes snapshots which are neither the newest one of a calendar hour
+// among the last keepHourly hours nor of a calendar day among the last keepDaily days
+// up to now and returns their names. Hours and days are taken in UTC and counted
+// whether they have snapshots or not. The newest snapshot is always kept.
+// Nothing is removed if both numbers of generations are zero
+func (s *Scheduler) Rotate(now time.Time) ([]string, error) {
+	if s.keepHourly <= 0 && s.keepDaily <= 0 {
+		return nil, nil
+	}
+
+	snapshots, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+
+	now = now.UTC()
+	thisHour := now.Truncate(time.Hour)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	firstHour := thisHour.Add(-time.Duration(s.keepHourly-1) * time.Hour)
+	firstDay := today.AddDate(0, 0, -(s.keepDaily - 1))
+
+	keep := make(map[string]bool)
+	hours := make(map[time.Time]bool)
+	days := make(map[time.Time]bool)
+
+	for i, sn := range snapshots {
+		hour := sn.at.Truncate(time.Hour)
+		day := time.Date(sn.at.Year(), sn.at.Month(), sn.at.Day(), 0, 0, 0, 0, time.UTC)
+
+		// Snapshots are sorted from the newest, so the first one of a bucket is its newest
+		if s.keepHourly > 0 && !hours[hour] && !hour.Before(firstHour) {
+			keep[sn.name] = true
+		}
+		hours[hour] = true
+
+		if s.keepDaily > 0 && !days[day] && !day.Before(firstDay) {
+			keep[sn.name] = true
+		}
+		days[day] = true
+
+		if i == 0 {
+			keep[sn.name] = true
+		}
+	}
+
+	var removed []string
+	for _, sn := range snapshots {
+		if keep[sn.name] {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, sn.name)); err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, sn.name)
+	}
+
+	return removed, nil
+}
+
+// list returns snapshots of the directory, the newest first
+func (s *Scheduler) list() ([]snapshot, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []snapshot
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+
+		at, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix))
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot{name: name, at: at})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].at.After(snapshots[j].at)
+	})
+
+	return snapshots, nil
+}
+
+// String describes the schedule for logs
+func (s *Scheduler) String() string {
+	return fmt.Sprintf("every %s into %s, keeping %d hourly and %d daily snapshots",
+		s.interval, s.dir, s.keepHourly, s.keepDaily)
+}
@@ -0,0 +1,180 @@
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBackuper writes a fixed content as a snapshot
+type fakeBackuper string
+
+func (b fakeBackuper) Backup(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, string(b))
+	return int64(n), err
+}
+
+// snapshotName returns a name of a snapshot taken at the time
+func snapshotName(at string) string {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		panic(err)
+	}
+
+	return snapshotPrefix + t.UTC().Format(snapshotTimeFormat) + snapshotSuffix
+}
+
+// rotate creates snapshots taken at the times, rotates them at now
+// and returns the times of the kept snapshots, the oldest first
+func rotate(t *testing.T, keepHourly, keepDaily int, now string, times ...string) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, at := range times {
+		if err := ioutil.WriteFile(filepath.Join(dir, snapshotName(at)), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	at, err := time.Parse(time.RFC3339, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScheduler(fakeBackuper(""), dir, time.Hour, keepHourly, keepDaily)
+	if _, err := s.Rotate(at); err != nil {
+		t.Fatalf("Rotate() failed: %v", err)
+	}
+
+	var kept []string
+	for _, at := range times {
+		if _, err := os.Stat(filepath.Join(dir, snapshotName(at))); err == nil {
+			kept = append(kept, at)
+		}
+	}
+
+	sort.Strings(kept)
+	return kept
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name                  string
+		keepHourly, keepDaily int
+		now                   string
+		times                 []string
+		want                  []string
+	}{
+		{
+			name:       "newest of each hour",
+			keepHourly: 3,
+			now:        "2026-03-10T12:30:00Z",
+			times: []string{
+				"2026-03-10T09:40:00Z", "2026-03-10T10:00:00Z", "2026-03-10T10:40:00Z",
+				"2026-03-10T11:20:00Z", "2026-03-10T12:00:00Z", "2026-03-10T12:20:00Z",
+			},
+			want: []string{"2026-03-10T10:40:00Z", "2026-03-10T11:20:00Z", "2026-03-10T12:20:00Z"},
+		},
+		{
+			name:       "hours without snapshots count",
+			keepHourly: 3,
+			now:        "2026-03-10T12:30:00Z",
+			times:      []string{"2026-03-10T09:10:00Z", "2026-03-10T09:50:00Z", "2026-03-10T12:10:00Z"},
+			want:       []string{"2026-03-10T12:10:00Z"},
+		},
+		{
+			name:      "newest of each day",
+			keepDaily: 2,
+			now:       "2026-03-10T12:30:00Z",
+			times: []string{
+				"2026-03-08T23:59:00Z", "2026-03-09T05:00:00Z",
+				"2026-03-09T23:00:00Z", "2026-03-10T01:00:00Z",
+			},
+			want: []string{"2026-03-09T23:00:00Z", "2026-03-10T01:00:00Z"},
+		},
+		{
+			name:      "days are taken in UTC",
+			keepDaily: 1,
+			now:       "2026-03-10T01:30:00+03:00",
+			times:     []string{"2026-03-09T20:00:00Z", "2026-03-09T21:00:00Z"},
+			want:      []string{"2026-03-09T21:00:00Z"},
+		},
+		{
+			name:       "hourly and daily generations",
+			keepHourly: 2,
+			keepDaily:  2,
+			now:        "2026-03-10T12:30:00Z",
+			times: []string{
+				"2026-03-09T10:00:00Z", "2026-03-09T18:00:00Z",
+				"2026-03-10T10:30:00Z", "2026-03-10T11:30:00Z", "2026-03-10T12:10:00Z",
+			},
+			want: []string{"2026-03-09T18:00:00Z", "2026-03-10T11:30:00Z", "2026-03-10T12:10:00Z"},
+		},
+		{
+			name:      "the newest snapshot is kept",
+			keepDaily: 2,
+			now:       "2026-03-10T12:30:00Z",
+			times:     []string{"2026-02-01T10:00:00Z", "2026-02-20T10:00:00Z"},
+			want:      []string{"2026-02-20T10:00:00Z"},
+		},
+		{
+			name:  "nothing is removed without generations",
+			now:   "2026-03-10T12:30:00Z",
+			times: []string{"2026-02-01T10:00:00Z", "2026-02-20T10:00:00Z"},
+			want:  []string{"2026-02-01T10:00:00Z", "2026-02-20T10:00:00Z"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := rotate(t, test.keepHourly, test.keepDaily, test.now, test.times...)
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("Rotate() kept %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRotateSkipsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"notes.txt", snapshotPrefix + "broken" + snapshotSuffix, snapshotName("2026-01-01T00:00:00Z")} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewScheduler(fakeBackuper(""), dir, time.Hour, 1, 1)
+	removed, err := s.Rotate(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(removed) != 0 {
+		t.Errorf("Rotate() = %v, %v, want nothing removed", removed, err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Errorf("the directory has %d files after Rotate(), want 3", len(files))
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	s := NewScheduler(fakeBackuper("data"), dir, time.Hour, 1, 1)
+
+	file, size, err := s.Snapshot(time.Date(2026, 3, 10, 12, 30, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Base(file) != "gbquestion-20260310-123005.db" || size != 4 {
+		t.Errorf("Snapshot() = %s of %d bytes, want gbquestion-20260310-123005.db of 4 bytes", file, size)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("the directory has %d files after Snapshot(), want only the snapshot", len(files))
+	}
+}
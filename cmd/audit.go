@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var auditCmd = &cobra.Command{
+	Use:     "audit",
+	Short:   "Show who changed questions and when",
+	PreRunE: initClient,
+	RunE:    audit,
+}
+
+func audit(cmd *cobra.Command, args []string) error {
+	req := &question.AuditRequest{}
+	req.QuestionId, _ = cmd.Flags().GetUint64("id")
+	req.Limit, _ = cmd.Flags().GetInt32("limit")
+	req.PageToken, _ = cmd.Flags().GetString("page-token")
+	since, _ := cmd.Flags().GetString("since")
+	all, _ := cmd.Flags().GetBool("all")
+	format, _ := cmd.Flags().GetString("format")
+	out, _ := cmd.Flags().GetString("out")
+
+	if since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			return err
+		}
+		req.Since = timestamppb.New(t)
+	}
+
+	var write func(e *question.AuditEntry) error
+	var flush func() error
+
+	switch format {
+	case "table":
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Time", "Method", "Caller", "Question", "Changes"})
+		table.SetAutoWrapText(false)
+		write = func(e *question.AuditEntry) error {
+			table.Append([]string{
+				e.Timestamp.AsTime().Local().Format("2006-01-02 15:04:05"),
+				e.Method,
+				e.Caller,
+				strconv.FormatUint(e.QuestionId, 10),
+				strings.Join(auditChanges(e), "\n"),
+			})
+			return nil
+		}
+		flush = func() error {
+			table.Render()
+			return nil
+		}
+	case "jsonl":
+		w := os.Stdout
+		if out != "" {
+			f, err := os.Create(out)
+			if err != nil {
+				return fmt.Errorf("Couldn't create an export file: %v", err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		bw := bufio.NewWriter(w)
+		marshaler := jsonpb.Marshaler{}
+		write = func(e *question.AuditEntry) error {
+			if err := marshaler.Marshal(bw, e); err != nil {
+				return err
+			}
+			return bw.WriteByte('\n')
+		}
+		flush = bw.Flush
+	default:
+		return fmt.Errorf("Unknown audit format %q", format)
+	}
+
+	for {
+		list, err := client.AuditLog(context.Background(), req)
+		if err != nil {
+			return fmt.Errorf("Couldn't read the audit log: %v", err)
+		}
+
+		for _, e := range list.Entries {
+			if err := write(e); err != nil {
+				return fmt.Errorf("Couldn't write the audit log: %v", err)
+			}
+		}
+
+		if list.NextPageToken == "" {
+			break
+		}
+
+		if !all {
+			fmt.Fprintf(os.Stderr, "Next page token: %s\n", list.NextPageToken)
+			break
+		}
+
+		req.PageToken = list.NextPageToken
+	}
+
+	if err := flush(); err != nil {
+		return fmt.Errorf("Couldn't write the audit log: %v", err)
+	}
+
+	return nil
+}
+
+// parseSince reads a time in RFC 3339 or a duration before now
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid since %q, it must be a time in RFC 3339 or a duration", value)
+	}
+
+	return time.Now().Add(-d), nil
+}
+
+// auditChanges describes a change of an audit entry.
+// Entries of purged questions have no questions to compare
+func auditChanges(e *question.AuditEntry) []string {
+	if e.Kind == question.AuditEntry_UPDATED && e.Before != nil && e.After != nil {
+		return diffQuestions(e.Before, e.After)
+	}
+
+	return []string{strings.ToLower(e.Kind.String())}
+}
+
+func init() {
+	auditCmd.Flags().Uint64P("id", "", 0, "Show only changes of the question")
+	auditCmd.Flags().String("since", "", "Show changes since the time in RFC 3339 or the duration ago, e.g. 24h")
+	auditCmd.Flags().Int32P("limit", "l", 100, "Number of entries on a page")
+	auditCmd.Flags().StringP("page-token", "p", "", "Token of the page to start from")
+	auditCmd.Flags().Bool("all", false, "Follow page tokens until all entries are fetched")
+	auditCmd.Flags().StringP("format", "f", "table", "Format of the output: table or jsonl")
+	auditCmd.Flags().StringP("out", "o", "", "Output file of jsonl, stdout by default")
+}
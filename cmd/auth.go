@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/almostmoore/gbquestion/auth"
+	"github.com/spf13/cobra"
+)
+
+// authInterceptor returns an interceptor for AUTH_KEYS_FILE and AUTH_JWT_SECRET
+// with the default policy or the one from AUTH_POLICY_FILE.
+// It returns nil if authentication isn't configured
+func authInterceptor(public ...string) (*auth.Interceptor, error) {
+	keysFile, secret := os.Getenv("AUTH_KEYS_FILE"), os.Getenv("AUTH_JWT_SECRET")
+	if keysFile == "" && secret == "" {
+		return nil, nil
+	}
+
+	var keys map[string]*auth.Identity
+	if keysFile != "" {
+		var err error
+		keys, err = auth.LoadKeys(keysFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load API keys: %v", err)
+		}
+	}
+
+	policy := auth.DefaultPolicy()
+	if policyFile := os.Getenv("AUTH_POLICY_FILE"); policyFile != "" {
+		var err error
+		policy, err = auth.LoadPolicy(policyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load a policy: %v", err)
+		}
+	}
+
+	return auth.NewInterceptor(auth.NewAuthenticator(keys, []byte(secret)), policy, public...), nil
+}
+
+// clientToken returns a token from the --token flag or TOKEN variable
+func clientToken(cmd *cobra.Command) string {
+	if token, _ := cmd.Flags().GetString("token"); token != "" {
+		return token
+	}
+
+	return os.Getenv("TOKEN")
+}
+
+// insecureToken reports whether the --insecure-token flag or INSECURE_TOKEN variable
+// allows sending a token over a plain connection
+func insecureToken(cmd *cobra.Command) bool {
+	if insecure, _ := cmd.Flags().GetBool("insecure-token"); insecure {
+		return true
+	}
+
+	return os.Getenv("INSECURE_TOKEN") == "true"
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Issue a JWT signed with AUTH_JWT_SECRET",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret := os.Getenv("AUTH_JWT_SECRET")
+		if secret == "" {
+			return fmt.Errorf("AUTH_JWT_SECRET is not set")
+		}
+
+		claims := &auth.Claims{IssuedAt: time.Now().Unix()}
+		claims.Subject, _ = cmd.Flags().GetString("subject")
+		claims.Role, _ = cmd.Flags().GetString("role")
+
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		if ttl > 0 {
+			claims.ExpiresAt = time.Now().Add(ttl).Unix()
+		}
+
+		token, err := auth.SignJWT(claims, []byte(secret))
+		if err != nil {
+			return fmt.Errorf("Couldn't sign a token: %v", err)
+		}
+
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func init() {
+	tokenCmd.Flags().StringP("subject", "s", "", "Name of the token owner")
+	tokenCmd.Flags().StringP("role", "r", "game", "Role of the token owner")
+	tokenCmd.Flags().Duration("ttl", 24*time.Hour, "Lifetime of the token, 0 means forever")
+}
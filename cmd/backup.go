@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var backupCmd, restoreCmd *cobra.Command
+
+// checksumSuffix is appended to a backup file name to get its checksum file
+const checksumSuffix = ".sha256"
+
+// gzipMagic starts every gzip file
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func backupDatabase(cmd *cobra.Command, args []string) error {
+	out, _ := cmd.Flags().GetString("out")
+	compress, _ := cmd.Flags().GetBool("gzip")
+	checksum, _ := cmd.Flags().GetBool("checksum")
+
+	if out == "" {
+		return fmt.Errorf("Output file must be set with --out")
+	}
+
+	stream, err := client.Backup(context.Background(), &question.Void{})
+	if err != nil {
+		return err
+	}
+
+	// The snapshot is written to a temporary file first,
+	// so a broken stream doesn't leave a partial backup
+	f, err := ioutil.TempFile(filepath.Dir(out), filepath.Base(out)+".part-")
+	if err != nil {
+		return fmt.Errorf("Couldn't create a backup file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	fileHash := sha256.New()
+	var w io.Writer = io.MultiWriter(f, fileHash)
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	snapshotHash := sha256.New()
+	w = io.MultiWriter(w, snapshotHash)
+
+	var size int64
+	var last *question.BackupChunk
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if chunk.Sha256 != "" {
+			last = chunk
+			continue
+		}
+
+		if _, err := w.Write(chunk.Data); err != nil {
+			return fmt.Errorf("Couldn't write the backup file: %v", err)
+		}
+		size += int64(len(chunk.Data))
+	}
+
+	if last == nil {
+		return fmt.Errorf("Backup stream ended without a checksum")
+	}
+
+	if sum := hex.EncodeToString(snapshotHash.Sum(nil)); size != last.Size || sum != last.Sha256 {
+		return fmt.Errorf("Received snapshot is corrupted: got %d bytes with SHA-256 %s, server sent %d bytes with %s",
+			size, sum, last.Size, last.Sha256)
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Couldn't write the backup file: %v", err)
+	}
+
+	if err := os.Rename(f.Name(), out); err != nil {
+		return fmt.Errorf("Couldn't write the backup file: %v", err)
+	}
+
+	if checksum {
+		line := fmt.Sprintf("%x  %s\n", fileHash.Sum(nil), filepath.Base(out))
+		if err := ioutil.WriteFile(out+checksumSuffix, []byte(line), 0644); err != nil {
+			return fmt.Errorf("Couldn't write the checksum file: %v", err)
+		}
+	}
+
+	fmt.Printf("Saved a snapshot of %d bytes to %s\n", size, out)
+	return nil
+}
+
+func restoreDatabase(cmd *cobra.Command, args []string) error {
+	in, _ := cmd.Flags().GetString("in")
+	target, _ := cmd.Flags().GetString("db")
+
+	if in == "" {
+		return fmt.Errorf("Backup file must be set with --in")
+	}
+
+	if target == "" {
+		target = os.Getenv("DB_PATH")
+	}
+
+	if driver := os.Getenv("DB_DRIVER"); driver != "" && driver != boltDriver {
+		return fmt.Errorf("Only bolt databases can be restored, DB_DRIVER is %s", driver)
+	}
+
+	if target == "" || target == memoryPath {
+		return fmt.Errorf("Database file must be set with --db or DB_PATH")
+	}
+
+	lockTimeout, err := durationEnv("DB_LOCK_TIMEOUT", defaultLockTimeout)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyChecksum(in); err != nil {
+		return err
+	}
+
+	tmp, err := unpackSnapshot(in, target)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	count, err := validateSnapshot(tmp, lockTimeout)
+	if err != nil {
+		return fmt.Errorf("Backup %s is broken: %v", in, err)
+	}
+
+	// Holding the lock of the current database makes sure
+	// no server uses it while the files are swapped
+	var previous string
+	if _, err := os.Stat(target); err == nil {
+		db, err := bolt.Open(target, 0600, &bolt.Options{Timeout: lockTimeout})
+		if err == bolt.ErrTimeout {
+			return fmt.Errorf("Database file (%s) is locked, stop the server before restoring", target)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Couldn't open database file (%s): %v", target, err)
+		}
+		defer db.Close()
+
+		previous = fmt.Sprintf("%s.before-restore-%s", target, time.Now().Format("20060102-150405"))
+		if err := os.Rename(target, previous); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %d questions into %s\n", count, target)
+	if previous != "" {
+		fmt.Printf("The previous database is kept in %s\n", previous)
+	}
+
+	return nil
+}
+
+// verifyChecksum compares the backup file with its checksum file if there is one
+func verifyChecksum(file string) error {
+	data, err := ioutil.ReadFile(file + checksumSuffix)
+	if os.IsNotExist(err) {
+		fmt.Printf("There is no %s, the checksum isn't verified\n", file+checksumSuffix)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("Checksum file %s is empty", file+checksumSuffix)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != fields[0] {
+		return fmt.Errorf("Checksum of %s is %s, but %s expects %s", file, sum, file+checksumSuffix, fields[0])
+	}
+
+	return nil
+}
+
+// unpackSnapshot copies the backup next to the target database,
+// decompressing it if it's gzipped, and returns the path of the copy
+func unpackSnapshot(in, target string) (string, error) {
+	f, err := os.Open(in)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(target), filepath.Base(target)+".restore-")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("Couldn't unpack %s: %v", in, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// validateSnapshot checks consistency of the bolt database
+// and that every question can be read, it returns the number of questions
+func validateSnapshot(path string, lockTimeout time.Duration) (int, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		var first error
+		for err := range tx.Check() {
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = question.NewStorage(db).ForEach(func(q *question.Question) error {
+		count++
+		return nil
+	})
+
+	return count, err
+}
+
+func init() {
+	backupCmd = &cobra.Command{
+		Use:     "backup",
+		Short:   "Save a snapshot of the database of a running server",
+		PreRunE: initClient,
+		RunE:    backupDatabase,
+	}
+
+	backupCmd.Flags().StringP("out", "o", "", "File to save the snapshot to")
+	backupCmd.Flags().Bool("gzip", false, "Compress the snapshot with gzip")
+	backupCmd.Flags().Bool("checksum", true, "Save SHA-256 of the file next to it")
+
+	restoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Replace the database with a snapshot, the server must be stopped",
+		RunE:  restoreDatabase,
+	}
+
+	restoreCmd.Flags().StringP("in", "i", "", "Snapshot file, plain or gzipped")
+	restoreCmd.Flags().String("db", "", "Database file to replace, DB_PATH by default")
+}
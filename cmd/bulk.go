@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+var importCmd, exportCmd *cobra.Command
+
+// csvHeader is a header row of exported and imported CSV files
+var csvHeader = []string{"id", "text", "isGood", "isActive", "category", "tags"}
+
+// csvTagsSeparator separates tags inside of a CSV cell
+const csvTagsSeparator = "|"
+
+// rowReader reads questions row by row from an import file
+// and returns the line the row starts at.
+// It returns a rowError if only the current row is broken
+type rowReader func() (*question.Question, uint64, error)
+
+// rowError describes a broken row of an import file
+type rowError struct {
+	err error
+}
+
+func (e rowError) Error() string {
+	return e.err.Error()
+}
+
+func export(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	out, _ := cmd.Flags().GetString("out")
+
+	w := os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("Couldn't create an export file: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var write func(q *question.Question) error
+	var flush func() error
+
+	switch format {
+	case "jsonl":
+		bw := bufio.NewWriter(w)
+		marshaler := jsonpb.Marshaler{EmitDefaults: true}
+		write = func(q *question.Question) error {
+			if err := marshaler.Marshal(bw, q); err != nil {
+				return err
+			}
+			return bw.WriteByte('\n')
+		}
+		flush = bw.Flush
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		write = func(q *question.Question) error {
+			return cw.Write([]string{
+				strconv.FormatUint(q.Id, 10),
+				q.Text,
+				strconv.FormatBool(q.IsGood),
+				strconv.FormatBool(q.IsActive),
+				q.Category,
+				strings.Join(q.Tags, csvTagsSeparator),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return fmt.Errorf("Unknown export format %q", format)
+	}
+
+	stream, err := client.Export(context.Background(), &question.Void{})
+	if err != nil {
+		return fmt.Errorf("Unable to export questions: %v", err)
+	}
+
+	for {
+		q, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Export was interrupted: %v", err)
+		}
+
+		if err := write(q); err != nil {
+			return fmt.Errorf("Couldn't write a question: %v", err)
+		}
+	}
+
+	return flush()
+}
+
+func importQuestions(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	preserveIds, _ := cmd.Flags().GetBool("preserve-ids")
+	duplicates, _ := cmd.Flags().GetString("duplicates")
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("Couldn't open an import file: %v", err)
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+
+	var next rowReader
+	switch format {
+	case "jsonl":
+		next = jsonlReader(f)
+	case "csv":
+		next, err = csvReader(f)
+		if err != nil {
+			return fmt.Errorf("Couldn't read CSV header: %v", err)
+		}
+	default:
+		return fmt.Errorf("Unknown import format %q", format)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), question.DuplicatesKey, duplicates)
+	stream, err := client.BulkPut(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to import questions: %v", err)
+	}
+
+	var rowErrors []*question.BulkPutError
+	for {
+		q, row, err := next()
+		if err == io.EOF {
+			break
+		}
+
+		if _, ok := err.(rowError); ok {
+			rowErrors = append(rowErrors, &question.BulkPutError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		if err != nil {
+			stream.CloseSend()
+			return fmt.Errorf("Couldn't read an import file: %v", err)
+		}
+
+		err = stream.Send(&question.BulkPutRequest{
+			Question:    q,
+			Row:         row,
+			DryRun:      dryRun,
+			PreserveIds: preserveIds,
+		})
+		if err != nil {
+			break
+		}
+	}
+
+	result, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("Import was interrupted: %v", err)
+	}
+
+	rowErrors = append(rowErrors, result.Errors...)
+	sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+
+	for _, e := range rowErrors {
+		fmt.Printf("Line %d: %s\n", e.Row, e.Message)
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Printf("Line %d: warning: %s\n", w.Row, w.Message)
+	}
+
+	if dryRun {
+		fmt.Print("Dry run: ")
+	}
+	fmt.Printf("%d created, %d updated, %d failed\n", result.Created, result.Updated, len(rowErrors))
+
+	return nil
+}
+
+// jsonlReader reads one JSON encoded question per line
+func jsonlReader(r io.Reader) rowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	var line uint64
+
+	return func() (*question.Question, uint64, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, 0, err
+			}
+			return nil, 0, io.EOF
+		}
+		line++
+
+		q := &question.Question{}
+		if err := jsonpb.UnmarshalString(scanner.Text(), q); err != nil {
+			return nil, line, rowError{err}
+		}
+
+		return q, line, nil
+	}
+}
+
+// csvReader reads questions from CSV rows with the columns named in the header.
+// Lines are counted from the header, so they match the file in an editor
+func csvReader(r io.Reader) (rowReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	return func() (*question.Question, uint64, error) {
+		record, err := cr.Read()
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, uint64(parseErr.StartLine), rowError{err}
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		startLine, _ := cr.FieldPos(0)
+		line := uint64(startLine)
+
+		value := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		q := &question.Question{
+			Text:     value("text"),
+			Category: value("category"),
+		}
+
+		if v := value("id"); v != "" {
+			if q.Id, err = strconv.ParseUint(v, 10, 64); err != nil {
+				return nil, line, rowError{fmt.Errorf("Invalid id %q", v)}
+			}
+		}
+
+		if v := value("isGood"); v != "" {
+			if q.IsGood, err = strconv.ParseBool(v); err != nil {
+				return nil, line, rowError{fmt.Errorf("Invalid isGood %q", v)}
+			}
+		}
+
+		if v := value("isActive"); v != "" {
+			if q.IsActive, err = strconv.ParseBool(v); err != nil {
+				return nil, line, rowError{fmt.Errorf("Invalid isActive %q", v)}
+			}
+		}
+
+		if v := value("tags"); v != "" {
+			q.Tags = strings.Split(v, csvTagsSeparator)
+		}
+
+		return q, line, nil
+	}, nil
+}
+
+func init() {
+	exportCmd = &cobra.Command{
+		Use:     "export",
+		Short:   "Export all questions",
+		PreRunE: initClient,
+		RunE:    export,
+	}
+
+	exportCmd.Flags().StringP("format", "f", "jsonl", "Format of the export: jsonl or csv")
+	exportCmd.Flags().StringP("out", "o", "", "Output file, stdout by default")
+
+	importCmd = &cobra.Command{
+		Use:     "import",
+		Short:   "Import questions from a file",
+		PreRunE: initClient,
+		RunE:    importQuestions,
+	}
+
+	importCmd.Flags().String("file", "", "JSONL or CSV file with questions")
+	importCmd.Flags().StringP("format", "f", "", "Format of the file: jsonl or csv, by default it's taken from the file extension")
+	importCmd.Flags().Bool("dry-run", false, "Validate questions without saving them")
+	importCmd.Flags().Bool("preserve-ids", false, "Keep IDs from the file instead of assigning new ones")
+	importCmd.Flags().String("duplicates", question.DuplicatesWarn, "What to do with similar questions: warn, reject or allow")
+}
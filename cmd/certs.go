@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/spf13/cobra"
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Generate throwaway TLS certificates for tests",
+	Long:  "Generates a CA, a server and a client certificate, which can be used as TLS_CA and TLS_CLIENT_CA, TLS_CERT/TLS_KEY and TLS_CLIENT_CERT/TLS_CLIENT_KEY",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("out")
+		hosts, _ := cmd.Flags().GetStringSlice("host")
+
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("Couldn't create a directory: %v", err)
+		}
+
+		if err := utils.GenerateCertificates(dir, hosts); err != nil {
+			return fmt.Errorf("Couldn't generate certificates: %v", err)
+		}
+
+		fmt.Printf("Certificates were written to %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	certsCmd.Flags().StringP("out", "o", "certs", "Directory for the certificates")
+	certsCmd.Flags().StringSlice("host", []string{"localhost", "127.0.0.1"}, "Hosts of the server certificate")
+}
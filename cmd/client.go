@@ -1,22 +1,45 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/almostmoore/gbquestion/auth"
 	"github.com/almostmoore/gbquestion/question"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	field_mask "google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 var client question.QuestionsClient
-var upsertCmd, listCmd, deleteCmd, viewCmd *cobra.Command
+var upsertCmd, listCmd, deleteCmd, viewCmd, randomCmd, watchCmd *cobra.Command
+var updateCmd, activateCmd, deactivateCmd *cobra.Command
 
 func initClient(cmd *cobra.Command, args []string) error {
-	conn, err := grpc.Dial(os.Getenv("LISTEN"), grpc.WithInsecure())
+	creds, err := clientCredentials()
+	if err != nil {
+		return err
+	}
+
+	opts := []grpc.DialOption{creds}
+	if token := clientToken(cmd); token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials{
+			Token:         token,
+			AllowInsecure: insecureToken(cmd),
+		}))
+	}
+
+	conn, err := grpc.Dial(os.Getenv("LISTEN"), opts...)
 	if err != nil {
 		return err
 	}
@@ -31,14 +54,91 @@ func upsert(cmd *cobra.Command, args []string) error {
 	q.Text, _ = cmd.Flags().GetString("text")
 	q.IsActive, _ = cmd.Flags().GetBool("active")
 	q.IsGood, _ = cmd.Flags().GetBool("good")
+	q.Category, _ = cmd.Flags().GetString("category")
+	q.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	q.Version, _ = cmd.Flags().GetUint64("expect-version")
+	duplicates, _ := cmd.Flags().GetString("duplicates")
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), question.DuplicatesKey, duplicates)
+
+	var header metadata.MD
+	q, err := client.Put(ctx, q, grpc.Header(&header))
+	switch status.Code(err) {
+	case codes.Aborted:
+		return fmt.Errorf("Question was changed by someone else: %s", status.Convert(err).Message())
+	case codes.AlreadyExists:
+		return fmt.Errorf("Question wasn't saved: %s", status.Convert(err).Message())
+	}
 
-	q, err := client.Put(context.Background(), q)
 	if err != nil {
 		return fmt.Errorf("Couldn't send a question: %v", err)
 	}
 
 	renderQuestions([]*question.Question{q})
 
+	if similar := header.Get(question.SimilarKey); len(similar) > 0 {
+		fmt.Printf("Warning: similar questions exist: %s\n", strings.Join(similar, ", "))
+	}
+
+	return nil
+}
+
+// updateFlags maps flags of the update command to field mask paths
+var updateFlags = map[string]string{
+	"text":     "text",
+	"good":     "isGood",
+	"active":   "isActive",
+	"category": "category",
+	"tag":      "tags",
+}
+
+func update(cmd *cobra.Command, args []string) error {
+	q := &question.Question{}
+	q.Id, _ = cmd.Flags().GetUint64("id")
+	q.Text, _ = cmd.Flags().GetString("text")
+	q.IsActive, _ = cmd.Flags().GetBool("active")
+	q.IsGood, _ = cmd.Flags().GetBool("good")
+	q.Category, _ = cmd.Flags().GetString("category")
+	q.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	q.Version, _ = cmd.Flags().GetUint64("expect-version")
+
+	mask := &field_mask.FieldMask{}
+	for flag, path := range updateFlags {
+		if cmd.Flags().Changed(flag) {
+			mask.Paths = append(mask.Paths, path)
+		}
+	}
+
+	if len(mask.Paths) == 0 {
+		return fmt.Errorf("Nothing to update, set at least one of the fields")
+	}
+
+	return sendUpdate(q, mask)
+}
+
+func setActive(isActive bool) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		q := &question.Question{IsActive: isActive}
+		q.Id, _ = cmd.Flags().GetUint64("id")
+
+		return sendUpdate(q, &field_mask.FieldMask{Paths: []string{"isActive"}})
+	}
+}
+
+func sendUpdate(q *question.Question, mask *field_mask.FieldMask) error {
+	updated, err := client.Update(context.Background(), &question.UpdateRequest{Question: q, UpdateMask: mask})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		fmt.Printf("Question %d not found\n", q.Id)
+		return nil
+	case codes.Aborted:
+		return fmt.Errorf("Question was changed by someone else: %s", status.Convert(err).Message())
+	default:
+		return fmt.Errorf("Couldn't update a question: %v", err)
+	}
+
+	renderQuestions([]*question.Question{updated})
 	return nil
 }
 
@@ -47,10 +147,55 @@ func list(cmd *cobra.Command, args []string) error {
 	filter.Limit, _ = cmd.Flags().GetInt32("limit")
 	filter.IsActive, _ = cmd.Flags().GetBool("active")
 	filter.Offset, _ = cmd.Flags().GetInt32("offset")
+	filter.PageToken, _ = cmd.Flags().GetString("page-token")
+	filter.Categories, _ = cmd.Flags().GetStringSlice("category")
+	filter.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	filter.AllTags, _ = cmd.Flags().GetBool("all-tags")
+	if cmd.Flags().Changed("good") {
+		isGood, _ := cmd.Flags().GetBool("good")
+		filter.IsGood = wrapperspb.Bool(isGood)
+	}
+	all, _ := cmd.Flags().GetBool("all")
+
+	var questions []*question.Question
+	for {
+		l, err := client.List(context.Background(), filter)
+		if err != nil {
+			return fmt.Errorf("Couldn't fetch a list of questions: %v", err)
+		}
+
+		questions = append(questions, l.Questions...)
+		if l.NextPageToken == "" {
+			break
+		}
+
+		if !all {
+			fmt.Printf("Next page token: %s\n", l.NextPageToken)
+			break
+		}
+
+		filter.PageToken = l.NextPageToken
+		filter.Offset = 0
+	}
+
+	renderQuestions(questions)
+	return nil
+}
+
+func random(cmd *cobra.Command, args []string) error {
+	req := &question.RandomRequest{}
+	req.Count, _ = cmd.Flags().GetInt32("count")
+	req.IsActive, _ = cmd.Flags().GetBool("active")
+	req.IsGood, _ = cmd.Flags().GetBool("good")
 
-	l, err := client.List(context.Background(), filter)
+	ignoreIds, _ := cmd.Flags().GetUintSlice("ignore")
+	for _, id := range ignoreIds {
+		req.IgnoreIds = append(req.IgnoreIds, uint64(id))
+	}
+
+	l, err := client.Random(context.Background(), req)
 	if err != nil {
-		return fmt.Errorf("Couldn't fetch a list of questions: %v", err)
+		return fmt.Errorf("Couldn't fetch random questions: %v", err)
 	}
 
 	renderQuestions(l.Questions)
@@ -62,6 +207,11 @@ func view(cmd *cobra.Command, args []string) error {
 	idRequest.Id, _ = cmd.Flags().GetUint64("id")
 
 	q, err := client.Get(context.Background(), idRequest)
+	if status.Code(err) == codes.NotFound {
+		fmt.Printf("Question %d not found\n", idRequest.Id)
+		return nil
+	}
+
 	if err != nil {
 		return fmt.Errorf("Unable to fetch a question: %v", err)
 	}
@@ -74,7 +224,17 @@ func delete(cmd *cobra.Command, args []string) error {
 	idRequest := &question.IdRequest{}
 	idRequest.Id, _ = cmd.Flags().GetUint64("id")
 
+	if !confirm(cmd, fmt.Sprintf("Delete question %d", idRequest.Id)) {
+		fmt.Println("Nothing was deleted")
+		return nil
+	}
+
 	_, err := client.Delete(context.Background(), idRequest)
+	if status.Code(err) == codes.NotFound {
+		fmt.Printf("Question %d not found\n", idRequest.Id)
+		return nil
+	}
+
 	if err != nil {
 		return fmt.Errorf("Unable to delete a question: %v", err)
 	}
@@ -83,9 +243,48 @@ func delete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func watch(cmd *cobra.Command, args []string) error {
+	req := &question.WatchRequest{}
+	req.FromRevision, _ = cmd.Flags().GetUint64("from")
+
+	stream, err := client.Watch(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Unable to watch questions: %v", err)
+	}
+
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("Watching was interrupted: %v", err)
+		}
+
+		fmt.Printf("%d\t%s\t%d\t%s\n", e.Revision, e.Type, e.Question.Id, e.Question.Text)
+	}
+}
+
+// confirm asks to confirm the action on stdin unless --yes is set
+func confirm(cmd *cobra.Command, action string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s? [y/N] ", action)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
+
 func renderQuestions(questions []*question.Question) {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Text", "Is Active", "Is Good"})
+	table.SetHeader([]string{"ID", "Text", "Is Active", "Is Good", "Category", "Tags", "Version"})
 
 	for _, q := range questions {
 		table.Append([]string{
@@ -93,6 +292,9 @@ func renderQuestions(questions []*question.Question) {
 			q.Text,
 			strconv.FormatBool(q.IsActive),
 			strconv.FormatBool(q.IsGood),
+			q.Category,
+			strings.Join(q.Tags, ", "),
+			strconv.FormatUint(q.Version, 10),
 		})
 	}
 
@@ -111,6 +313,43 @@ func init() {
 	upsertCmd.Flags().Uint64P("id", "", 0, "ID of the question")
 	upsertCmd.Flags().BoolP("active", "a", true, "Flag of activity")
 	upsertCmd.Flags().BoolP("good", "g", true, "Is it a good answer?")
+	upsertCmd.Flags().StringP("category", "c", "", "Category of the question")
+	upsertCmd.Flags().StringSlice("tag", nil, "Tags of the question")
+	upsertCmd.Flags().Uint64("expect-version", 0, "Fail if the stored version of the question differs")
+	upsertCmd.Flags().String("duplicates", question.DuplicatesWarn, "What to do with similar questions: warn, reject or allow")
+
+	updateCmd = &cobra.Command{
+		Use:     "update",
+		Short:   "Change only the given fields of a question",
+		PreRunE: initClient,
+		RunE:    update,
+	}
+
+	updateCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+	updateCmd.Flags().StringP("text", "t", "", "Text of the question")
+	updateCmd.Flags().BoolP("active", "a", true, "Flag of activity")
+	updateCmd.Flags().BoolP("good", "g", true, "Is it a good answer?")
+	updateCmd.Flags().StringP("category", "c", "", "Category of the question")
+	updateCmd.Flags().StringSlice("tag", nil, "Tags of the question")
+	updateCmd.Flags().Uint64("expect-version", 0, "Fail if the stored version of the question differs")
+
+	activateCmd = &cobra.Command{
+		Use:     "activate",
+		Short:   "Activate a question",
+		PreRunE: initClient,
+		RunE:    setActive(true),
+	}
+
+	activateCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+
+	deactivateCmd = &cobra.Command{
+		Use:     "deactivate",
+		Short:   "Deactivate a question",
+		PreRunE: initClient,
+		RunE:    setActive(false),
+	}
+
+	deactivateCmd.Flags().Uint64P("id", "", 0, "ID of the question")
 
 	listCmd = &cobra.Command{
 		Use:     "list",
@@ -122,6 +361,24 @@ func init() {
 	listCmd.Flags().Int32P("limit", "l", 100, "Limit of questions")
 	listCmd.Flags().Int32P("offset", "o", 0, "Offset from the start")
 	listCmd.Flags().BoolP("active", "a", true, "Show only active or disabled question")
+	listCmd.Flags().BoolP("good", "g", false, "Show only good or bad questions, both by default")
+	listCmd.Flags().StringP("page-token", "p", "", "Token of the page to start from")
+	listCmd.Flags().Bool("all", false, "Follow page tokens until all questions are fetched")
+	listCmd.Flags().StringSliceP("category", "c", nil, "Show only questions from any of the categories")
+	listCmd.Flags().StringSlice("tag", nil, "Show only questions with any of the tags")
+	listCmd.Flags().Bool("all-tags", false, "Require all of the tags instead of any")
+
+	randomCmd = &cobra.Command{
+		Use:     "random",
+		Short:   "Show random questions",
+		PreRunE: initClient,
+		RunE:    random,
+	}
+
+	randomCmd.Flags().Int32P("count", "c", 1, "Number of questions")
+	randomCmd.Flags().BoolP("active", "a", true, "Pick only active or disabled questions")
+	randomCmd.Flags().BoolP("good", "g", true, "Pick only good or bad questions")
+	randomCmd.Flags().UintSlice("ignore", nil, "IDs of questions to skip")
 
 	viewCmd = &cobra.Command{
 		Use:     "view",
@@ -132,6 +389,15 @@ func init() {
 
 	viewCmd.Flags().Uint64P("id", "", 0, "Id of a question")
 
+	watchCmd = &cobra.Command{
+		Use:     "watch",
+		Short:   "Print changes of questions as they happen",
+		PreRunE: initClient,
+		RunE:    watch,
+	}
+
+	watchCmd.Flags().Uint64P("from", "f", 0, "Resume after this revision")
+
 	deleteCmd = &cobra.Command{
 		Use:     "delete",
 		Short:   "Delete one question",
@@ -140,4 +406,5 @@ func init() {
 	}
 
 	deleteCmd.Flags().Uint64P("id", "", 0, "Id of a question")
+	deleteCmd.Flags().BoolP("yes", "y", false, "Delete without confirmation")
 }
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:     "dedupe",
+	Short:   "Show clusters of similar questions",
+	PreRunE: initClient,
+	RunE:    dedupe,
+}
+
+func dedupe(cmd *cobra.Command, args []string) error {
+	req := &question.DuplicatesRequest{}
+	req.Threshold, _ = cmd.Flags().GetFloat64("threshold")
+
+	result, err := client.Duplicates(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Couldn't look for duplicates: %v", err)
+	}
+
+	for i, cluster := range result.Clusters {
+		questions := make([]*question.Question, 0, len(cluster.Ids))
+		for _, id := range cluster.Ids {
+			q, err := client.Get(context.Background(), &question.IdRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("Couldn't get question %d: %v", id, err)
+			}
+
+			questions = append(questions, q)
+		}
+
+		fmt.Printf("Cluster %d\n", i+1)
+		renderQuestions(questions)
+	}
+
+	fmt.Printf("Found %d clusters of similar questions\n", len(result.Clusters))
+	return nil
+}
+
+func init() {
+	dedupeCmd.Flags().Float64("threshold", question.DefaultSimilarity, "Minimal similarity of texts from 0 to 1")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check health of the server, exits with non-zero code when it is unhealthy",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service, _ := cmd.Flags().GetString("service")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		creds, err := clientCredentials()
+		if err != nil {
+			return err
+		}
+
+		conn, err := grpc.Dial(os.Getenv("LISTEN"), creds)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return fmt.Errorf("Health check failed: %v", err)
+		}
+
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("Server is %s", resp.Status)
+		}
+
+		fmt.Println(resp.Status)
+		return nil
+	},
+}
+
+func init() {
+	healthCmd.Flags().StringP("service", "s", questionsService, "Name of the service to check, empty for the whole server")
+	healthCmd.Flags().Duration("timeout", 5*time.Second, "Timeout of the check")
+}
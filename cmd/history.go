@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var historyCmd = &cobra.Command{
+	Use:     "history",
+	Short:   "Show versions of a question and what changed in each of them",
+	PreRunE: initClient,
+	RunE:    history,
+}
+
+var revertCmd = &cobra.Command{
+	Use:     "revert",
+	Short:   "Write a question as it was at an earlier version",
+	PreRunE: initClient,
+	RunE:    revert,
+}
+
+func history(cmd *cobra.Command, args []string) error {
+	id, _ := cmd.Flags().GetUint64("id")
+
+	list, err := client.History(context.Background(), &question.IdRequest{Id: id})
+	if err != nil {
+		return fmt.Errorf("Couldn't get history of question %d: %v", id, err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Version", "Time", "Author", "Changes"})
+	table.SetAutoWrapText(false)
+
+	var previous *question.Question
+	for _, rev := range list.Revisions {
+		var changes []string
+		switch {
+		case previous != nil:
+			changes = diffQuestions(previous, rev.Question)
+		case rev.Question.Version == 1:
+			changes = []string{"created"}
+		}
+
+		var at string
+		if rev.Timestamp != nil {
+			at = rev.Timestamp.AsTime().Local().Format("2006-01-02 15:04:05")
+		}
+
+		table.Append([]string{
+			strconv.FormatUint(rev.Question.Version, 10),
+			at,
+			rev.Author,
+			strings.Join(changes, "\n"),
+		})
+
+		previous = rev.Question
+	}
+
+	table.Render()
+	return nil
+}
+
+func revert(cmd *cobra.Command, args []string) error {
+	req := &question.RevertRequest{}
+	req.Id, _ = cmd.Flags().GetUint64("id")
+	req.ToVersion, _ = cmd.Flags().GetUint64("to-version")
+	req.Version, _ = cmd.Flags().GetUint64("expect-version")
+
+	before, err := client.Get(context.Background(), &question.IdRequest{Id: req.Id})
+	if err != nil {
+		return fmt.Errorf("Couldn't get question %d: %v", req.Id, err)
+	}
+
+	after, err := client.Revert(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Couldn't revert question %d: %v", req.Id, err)
+	}
+
+	renderQuestions([]*question.Question{after})
+
+	changes := diffQuestions(before, after)
+	if len(changes) == 0 {
+		fmt.Println("Nothing has changed")
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	return nil
+}
+
+// diffQuestions describes changes of the text, flags, category and tags between two versions
+func diffQuestions(a, b *question.Question) []string {
+	var changes []string
+
+	diff := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, from, to))
+		}
+	}
+
+	diff("text", strconv.Quote(a.Text), strconv.Quote(b.Text))
+	diff("active", strconv.FormatBool(a.IsActive), strconv.FormatBool(b.IsActive))
+	diff("good", strconv.FormatBool(a.IsGood), strconv.FormatBool(b.IsGood))
+	diff("category", strconv.Quote(a.Category), strconv.Quote(b.Category))
+	diff("tags", "["+strings.Join(a.Tags, ", ")+"]", "["+strings.Join(b.Tags, ", ")+"]")
+
+	return changes
+}
+
+func init() {
+	historyCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+
+	revertCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+	revertCmd.Flags().Uint64("to-version", 0, "Version to revert to")
+	revertCmd.Flags().Uint64("expect-version", 0, "Fail if the stored version of the question differs")
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy all questions to another storage",
+	Long: "Migrate copies questions with their IDs and versions and the ID sequence " +
+		"from one storage to another, e.g. --from bolt:///old.db --to sqlite:///new.db. " +
+		"Questions are read from one snapshot of the source, writes made after it aren't copied. " +
+		"Progress is saved to a checkpoint file, so an interrupted migration continues " +
+		"where it stopped. The target is compared with the snapshot when all questions are copied",
+	RunE: migrate,
+}
+
+// migrateCheckpoint is saved after every copied batch of questions
+type migrateCheckpoint struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	LastId uint64 `json:"lastId"`
+}
+
+// storeDigest describes contents of a store to compare it with another one
+type storeDigest struct {
+	counts   question.Counts
+	total    uint64
+	checksum []byte
+	sequence uint64
+}
+
+func migrate(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	checkpointFile, _ := cmd.Flags().GetString("checkpoint")
+	batchSize, _ := cmd.Flags().GetInt("batch")
+	restart, _ := cmd.Flags().GetBool("restart")
+
+	if from == "" || to == "" {
+		return fmt.Errorf("Both --from and --to must be set")
+	}
+
+	if batchSize <= 0 {
+		return fmt.Errorf("Batch size must be positive")
+	}
+
+	lockTimeout, err := durationEnv("DB_LOCK_TIMEOUT", defaultLockTimeout)
+	if err != nil {
+		return err
+	}
+
+	src, closeSrc, err := openStoreURL(from, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+
+	dst, closeDst, err := openStoreURL(to, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer closeDst()
+
+	checkpoint := &migrateCheckpoint{From: from, To: to}
+	if !restart {
+		checkpoint, err = readCheckpoint(checkpointFile, from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	if checkpoint.LastId > 0 {
+		fmt.Printf("Resuming after question %d\n", checkpoint.LastId)
+	}
+
+	var copied int
+	batch := make([]question.Question, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := dst.Load(batch); err != nil {
+			return fmt.Errorf("Couldn't write questions: %v", err)
+		}
+
+		copied += len(batch)
+		checkpoint.LastId = batch[len(batch)-1].Id
+		batch = batch[:0]
+
+		if err := writeCheckpoint(checkpointFile, checkpoint); err != nil {
+			return err
+		}
+
+		fmt.Printf("Copied %d questions, last ID %d\n", copied, checkpoint.LastId)
+		return nil
+	}
+
+	source := newDigester()
+	seq, err := readSnapshot(src, func(q *question.Question) error {
+		if err := source.add(q); err != nil {
+			return err
+		}
+
+		if q.Id <= checkpoint.LastId {
+			return nil
+		}
+
+		batch = append(batch, *q)
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if err := dst.SetSequence(seq); err != nil {
+		return fmt.Errorf("Couldn't move the sequence: %v", err)
+	}
+
+	if err := verifyMigration(source.digest(seq), dst); err != nil {
+		return fmt.Errorf("%v. Run migrate with --restart to copy everything again", err)
+	}
+
+	if err := os.Remove(checkpointFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	fmt.Println("Migration is complete")
+	return nil
+}
+
+// openStoreURL opens and initializes a store by URL like bolt:///path/to/data.db.
+// The scheme is a DB_DRIVER value
+func openStoreURL(rawURL string, lockTimeout time.Duration) (question.Store, func() error, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid storage URL %q: %v", rawURL, err)
+	}
+
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+
+	if path == "" {
+		return nil, nil, fmt.Errorf("Storage URL %q has no path", rawURL)
+	}
+
+	s, _, closeStore, err := openDriver(u.Scheme, path, lockTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.Init(); err != nil {
+		closeStore()
+		return nil, nil, fmt.Errorf("Couldn't initialize the storage (%s): %v", rawURL, err)
+	}
+
+	return s, closeStore, nil
+}
+
+// readCheckpoint returns the saved progress of the same migration
+// or an empty one if there is no checkpoint file
+func readCheckpoint(file, from, to string) (*migrateCheckpoint, error) {
+	checkpoint := &migrateCheckpoint{From: from, To: to}
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read the checkpoint: %v", err)
+	}
+
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("Checkpoint %s is broken: %v", file, err)
+	}
+
+	if checkpoint.From != from || checkpoint.To != to {
+		return nil, fmt.Errorf("Checkpoint %s belongs to the migration from %s to %s, use --restart or another --checkpoint",
+			file, checkpoint.From, checkpoint.To)
+	}
+
+	return checkpoint, nil
+}
+
+// writeCheckpoint replaces the checkpoint file atomically
+func writeCheckpoint(file string, checkpoint *migrateCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(file+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("Couldn't save the checkpoint: %v", err)
+	}
+
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return fmt.Errorf("Couldn't save the checkpoint: %v", err)
+	}
+
+	return nil
+}
+
+// readSnapshot calls fn for every question of the store in order of IDs
+// and returns the sequence. Questions are read from one snapshot
+// if the store supports it
+func readSnapshot(s question.Store, fn func(q *question.Question) error) (uint64, error) {
+	if snapshotter, ok := s.(question.Snapshotter); ok {
+		return snapshotter.Snapshot(fn)
+	}
+
+	if err := s.ForEach(fn); err != nil {
+		return 0, err
+	}
+
+	return s.Sequence()
+}
+
+// verifyMigration compares numbers of questions, checksums of them
+// and sequences of the source snapshot and the target
+func verifyMigration(want *storeDigest, dst question.Store) error {
+	target := newDigester()
+	seq, err := readSnapshot(dst, target.add)
+	if err != nil {
+		return fmt.Errorf("Couldn't read the target: %v", err)
+	}
+
+	got := target.digest(seq)
+
+	// Counts of the target are read from its indexes, so they are checked too
+	counts, err := dst.Count()
+	if err != nil {
+		return fmt.Errorf("Couldn't count questions of the target: %v", err)
+	}
+
+	if *counts != got.counts {
+		return fmt.Errorf("Verification failed: target has %d questions (%d active), but counts %d (%d active)",
+			got.total, got.counts.Active, counts.Active+counts.Inactive, counts.Active)
+	}
+
+	if got.counts != want.counts || got.total != want.total {
+		return fmt.Errorf("Verification failed: source has %d questions (%d active), target has %d (%d active)",
+			want.total, want.counts.Active, got.total, got.counts.Active)
+	}
+
+	if !bytes.Equal(got.checksum, want.checksum) {
+		return fmt.Errorf("Verification failed: checksums differ, source %x, target %x", want.checksum, got.checksum)
+	}
+
+	if got.sequence < want.sequence {
+		return fmt.Errorf("Verification failed: target sequence %d is behind %d", got.sequence, want.sequence)
+	}
+
+	fmt.Printf("Verified %d questions, checksum %x\n", got.total, got.checksum)
+	return nil
+}
+
+// digester computes a digest of questions added in order of IDs
+type digester struct {
+	d storeDigest
+	h hash.Hash
+}
+
+func newDigester() *digester {
+	return &digester{h: sha256.New()}
+}
+
+// add counts the question and adds it to the checksum
+func (dg *digester) add(q *question.Question) error {
+	data, err := proto.Marshal(q)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(dg.h, "%d:", len(data))
+	dg.h.Write(data)
+
+	dg.d.total++
+	if q.IsActive {
+		dg.d.counts.Active++
+	} else {
+		dg.d.counts.Inactive++
+	}
+
+	return nil
+}
+
+// digest returns the digest of the added questions with the sequence
+func (dg *digester) digest(seq uint64) *storeDigest {
+	d := dg.d
+	d.checksum = dg.h.Sum(nil)
+	d.sequence = seq
+	return &d
+}
+
+func init() {
+	migrateCmd.Flags().String("from", "", "Source storage URL, e.g. bolt:///path/to/data.db")
+	migrateCmd.Flags().String("to", "", "Target storage URL, e.g. sqlite:///path/to/data.sqlite")
+	migrateCmd.Flags().String("checkpoint", "migrate.checkpoint", "File with the progress of the migration")
+	migrateCmd.Flags().Int("batch", 500, "Number of questions written in one transaction")
+	migrateCmd.Flags().Bool("restart", false, "Ignore the checkpoint and copy all questions again")
+}
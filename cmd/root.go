@@ -17,9 +17,32 @@ var RootCmd = &cobra.Command{
 }
 
 func init() {
+	RootCmd.PersistentFlags().String("token", "", "Token for the server, TOKEN variable is used by default")
+	RootCmd.PersistentFlags().Bool("insecure-token", false, "Send the token without TLS, INSECURE_TOKEN=true does the same")
+
 	RootCmd.AddCommand(server)
 	RootCmd.AddCommand(upsertCmd)
 	RootCmd.AddCommand(listCmd)
 	RootCmd.AddCommand(deleteCmd)
 	RootCmd.AddCommand(viewCmd)
+	RootCmd.AddCommand(randomCmd)
+	RootCmd.AddCommand(watchCmd)
+	RootCmd.AddCommand(updateCmd)
+	RootCmd.AddCommand(activateCmd)
+	RootCmd.AddCommand(deactivateCmd)
+	RootCmd.AddCommand(importCmd)
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(certsCmd)
+	RootCmd.AddCommand(tokenCmd)
+	RootCmd.AddCommand(healthCmd)
+	RootCmd.AddCommand(migrateCmd)
+	RootCmd.AddCommand(backupCmd)
+	RootCmd.AddCommand(restoreCmd)
+	RootCmd.AddCommand(searchCmd)
+	RootCmd.AddCommand(reindexCmd)
+	RootCmd.AddCommand(dedupeCmd)
+	RootCmd.AddCommand(historyCmd)
+	RootCmd.AddCommand(revertCmd)
+	RootCmd.AddCommand(trashCmd)
+	RootCmd.AddCommand(auditCmd)
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var searchCmd = &cobra.Command{
+	Use:     "search <words>",
+	Short:   "Find questions by words of their text, volc* matches words starting with volc",
+	Args:    cobra.MinimumNArgs(1),
+	PreRunE: initClient,
+	RunE:    search,
+}
+
+var reindexCmd = &cobra.Command{
+	Use:     "reindex",
+	Short:   "Rebuild the search index of the server",
+	PreRunE: initClient,
+	RunE:    reindex,
+}
+
+func search(cmd *cobra.Command, args []string) error {
+	req := &question.SearchRequest{Query: strings.Join(args, " ")}
+	req.Limit, _ = cmd.Flags().GetInt32("limit")
+	req.Offset, _ = cmd.Flags().GetInt32("offset")
+	req.OnlyActive, _ = cmd.Flags().GetBool("only-active")
+
+	l, err := client.Search(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Couldn't search questions: %v", err)
+	}
+
+	renderQuestions(l.Questions)
+	return nil
+}
+
+func reindex(cmd *cobra.Command, args []string) error {
+	result, err := client.Reindex(context.Background(), &question.Void{})
+	if err != nil {
+		return fmt.Errorf("Couldn't rebuild the search index: %v", err)
+	}
+
+	fmt.Printf("Indexed %d questions\n", result.Questions)
+	return nil
+}
+
+func init() {
+	searchCmd.Flags().Int32P("limit", "l", 20, "Limit of questions")
+	searchCmd.Flags().Int32P("offset", "o", 0, "Offset from the start")
+	searchCmd.Flags().Bool("only-active", false, "Show only active questions")
+}
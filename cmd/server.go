@@ -1,38 +1,476 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/almostmoore/gbquestion/backup"
+	"github.com/almostmoore/gbquestion/gateway"
+	"github.com/almostmoore/gbquestion/metrics"
 	"github.com/almostmoore/gbquestion/question"
-	"github.com/boltdb/bolt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+// questionsService is a name of the questions service for health checks
+const questionsService = "question.Questions"
+
+// publicMethods are called without authentication
+var publicMethods = []string{
+	"/grpc.health.v1.Health/Check",
+	"/grpc.health.v1.Health/Watch",
+}
+
 var server = &cobra.Command{
 	Use:   "serve",
 	Short: "Run a questions grpc server",
 	Long:  "Server will read .env file and work with it",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		db, err := bolt.Open(os.Getenv("DB_PATH"), 0600, nil)
+		// Signals are caught from the start, so a shutdown during a long
+		// initialization of the storage rolls it back instead of killing the process
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+		lockTimeout, err := durationEnv("DB_LOCK_TIMEOUT", defaultLockTimeout)
 		if err != nil {
-			log.Fatalf("Couldn't load database file (%s): %s", os.Getenv("DB_PATH"), err)
+			log.Fatal(err)
 		}
-		defer db.Close()
 
-		qs := question.NewStorage(db)
+		drainTimeout, err := durationEnv("SHUTDOWN_TIMEOUT", defaultDrainTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		qs, db, closeStore, err := openStore(lockTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		qs, err = keepVersions(qs)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		tlsConfig, err := serverTLSConfig()
+		if err != nil {
+			log.Fatalf("Couldn't configure TLS: %v", err)
+		}
+
+		var opts []grpc.ServerOption
+		if tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
+		requests := &requestCounter{}
+		starting := &startupGate{}
+		unary := []grpc.UnaryServerInterceptor{requests.unary, starting.unary}
+		stream := []grpc.StreamServerInterceptor{requests.stream, starting.stream}
+
+		if addr := os.Getenv("METRICS_LISTEN"); addr != "" {
+			prometheus.MustRegister(metrics.NewStorageCollector(qs, db))
+			unary = append(unary, metrics.UnaryServerInterceptor())
+			stream = append(stream, metrics.StreamServerInterceptor())
+
+			go serveMetrics(addr)
+		}
+
+		authenticator, err := authInterceptor(publicMethods...)
+		if err != nil {
+			log.Fatalf("Couldn't configure authentication: %v", err)
+		}
+
+		if authenticator != nil {
+			unary = append(unary, authenticator.Unary())
+			stream = append(stream, authenticator.Stream())
+		}
+
+		opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
 
 		service := question.NewRPCService(qs)
-		srv := grpc.NewServer()
+		srv := grpc.NewServer(opts...)
 
 		question.RegisterQuestionsServer(srv, service)
+
+		healthSrv := health.NewServer()
+		healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthSrv.SetServingStatus(questionsService, healthpb.HealthCheckResponse_NOT_SERVING)
+		healthpb.RegisterHealthServer(srv, healthSrv)
+
+		if os.Getenv("REFLECTION") == "true" {
+			reflection.Register(srv)
+		}
+
+		var gatewaySrv *http.Server
+		if addr := os.Getenv("HTTP_LISTEN"); addr != "" {
+			gl, err := net.Listen("tcp", addr)
+			if err != nil {
+				log.Fatalf("Couldn't start listening a port for the HTTP gateway: %v", err)
+			}
+
+			gatewaySrv = &http.Server{Handler: gateway.New(service, unary...)}
+			if tlsConfig != nil {
+				gatewaySrv.TLSConfig = tlsConfig.Clone()
+			}
+			go serveGateway(gatewaySrv, gl)
+		}
+
 		l, err := net.Listen("tcp", os.Getenv("LISTEN"))
 		if err != nil {
 			log.Fatalf("Couldn't start listening a port: %v", err)
 		}
 
-		return srv.Serve(l)
+		served := make(chan error, 1)
+		go func() {
+			served <- srv.Serve(l)
+		}()
+
+		// Building missing indexes may take a while, so the server is reported
+		// as not serving and rejects calls other than health checks until it's done
+		initCtx, cancelInit := context.WithCancel(context.Background())
+		defer cancelInit()
+
+		initialized := make(chan error, 1)
+		go func() {
+			initialized <- initStore(initCtx, qs)
+		}()
+
+		select {
+		case err := <-initialized:
+			if err != nil {
+				log.Fatalf("Couldn't initialize the storage: %v", err)
+			}
+		case sig := <-signals:
+			log.Printf("Got %s while initializing the storage, shutting down", sig)
+			cancelInit()
+			if err := <-initialized; err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Couldn't initialize the storage: %v", err)
+			}
+
+			srv.Stop()
+			if gatewaySrv != nil {
+				gatewaySrv.Close()
+			}
+			return closeStore()
+		}
+
+		starting.open()
+		healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		healthSrv.SetServingStatus(questionsService, healthpb.HealthCheckResponse_SERVING)
+
+		backups, err := backupScheduler(qs)
+		if err != nil {
+			log.Fatalf("Couldn't configure backups: %v", err)
+		}
+
+		if backups != nil {
+			log.Printf("Backing up the database %s", backups)
+			backups.Start()
+		}
+
+		purger, err := trashPurger(qs)
+		if err != nil {
+			log.Fatalf("Couldn't configure purging of the trash: %v", err)
+		}
+
+		if purger != nil {
+			purger.Start()
+		}
+
+		select {
+		case err := <-served:
+			if gatewaySrv != nil {
+				gatewaySrv.Close()
+			}
+			stopBackups(backups)
+			stopTrashPurger(purger)
+			closeStore()
+			return err
+		case sig := <-signals:
+			log.Printf("Got %s, shutting down", sig)
+		}
+
+		healthSrv.Shutdown()
+		pending := requests.active()
+
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+
+		gatewayStopped := make(chan error, 1)
+		if gatewaySrv != nil {
+			go func() {
+				gatewayStopped <- gatewaySrv.Shutdown(ctx)
+			}()
+		} else {
+			gatewayStopped <- nil
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			log.Printf("Requests weren't drained in %s, stopping forcibly", drainTimeout)
+			srv.Stop()
+		}
+
+		if err := <-gatewayStopped; err != nil {
+			log.Printf("HTTP requests weren't drained in %s, closing connections", drainTimeout)
+			gatewaySrv.Close()
+		}
+
+		log.Printf("Drained %d of %d requests", pending-requests.active(), pending)
+
+		stopBackups(backups)
+		stopTrashPurger(purger)
+		return closeStore()
 	},
 }
+
+const (
+	defaultLockTimeout      = time.Second
+	defaultDrainTimeout     = 10 * time.Second
+	defaultBackupInterval   = time.Hour
+	defaultBackupKeepHourly = 24
+	defaultBackupKeepDaily  = 7
+	defaultTrashRetention   = 30
+	trashPurgeInterval      = time.Hour
+)
+
+// durationEnv reads a duration from the environment variable
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s: %v", name, err)
+	}
+
+	return d, nil
+}
+
+// intEnv reads an integer from the environment variable
+func intEnv(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s: %v", name, err)
+	}
+
+	return n, nil
+}
+
+// backupScheduler configures scheduled backups from BACKUP_ variables.
+// It returns nil if BACKUP_DIR isn't set
+func backupScheduler(s question.Store) (*backup.Scheduler, error) {
+	dir := os.Getenv("BACKUP_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+
+	backuper, ok := s.(question.Backuper)
+	if !ok {
+		return nil, fmt.Errorf("BACKUP_DIR is set, but the storage doesn't support backups")
+	}
+
+	interval, err := durationEnv("BACKUP_INTERVAL", defaultBackupInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("BACKUP_INTERVAL must be positive")
+	}
+
+	keepHourly, err := intEnv("BACKUP_KEEP_HOURLY", defaultBackupKeepHourly)
+	if err != nil {
+		return nil, err
+	}
+
+	keepDaily, err := intEnv("BACKUP_KEEP_DAILY", defaultBackupKeepDaily)
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.NewScheduler(backuper, dir, interval, keepHourly, keepDaily), nil
+}
+
+// stopBackups waits for a running backup, so the database can be closed
+func stopBackups(backups *backup.Scheduler) {
+	if backups != nil {
+		backups.Stop()
+	}
+}
+
+// initStore initializes the store, stores which support it stop when ctx is done
+func initStore(ctx context.Context, s question.Store) error {
+	if initer, ok := s.(question.CancelableIniter); ok {
+		return initer.InitContext(ctx)
+	}
+
+	return s.Init()
+}
+
+// keepVersions limits the history of every question to HISTORY_KEEP_VERSIONS latest versions.
+// All versions are kept if it is unset or 0. Stores without history are returned as they are
+func keepVersions(s question.Store) (question.Store, error) {
+	historian, ok := s.(question.Historian)
+	if !ok {
+		return s, nil
+	}
+
+	n, err := intEnv("HISTORY_KEEP_VERSIONS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		return nil, fmt.Errorf("HISTORY_KEEP_VERSIONS must not be negative")
+	}
+
+	return historian.KeepVersions(n), nil
+}
+
+// trashPurger configures purging of questions deleted more than TRASH_RETENTION_DAYS ago.
+// It returns nil if the storage has no trash or TRASH_RETENTION_DAYS is 0
+func trashPurger(s question.Store) (*question.TrashPurger, error) {
+	trasher, ok := s.(question.Trasher)
+	if !ok {
+		return nil, nil
+	}
+
+	days, err := intEnv("TRASH_RETENTION_DAYS", defaultTrashRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	if days < 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS must not be negative")
+	}
+
+	if days == 0 {
+		return nil, nil
+	}
+
+	return question.NewTrashPurger(trasher, time.Duration(days)*24*time.Hour, trashPurgeInterval), nil
+}
+
+// stopTrashPurger waits for a running purge, so the database can be closed
+func stopTrashPurger(purger *question.TrashPurger) {
+	if purger != nil {
+		purger.Stop()
+	}
+}
+
+// requestCounter counts requests which are being handled
+type requestCounter struct {
+	n int64
+}
+
+func (c *requestCounter) active() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+func (c *requestCounter) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	atomic.AddInt64(&c.n, 1)
+	defer atomic.AddInt64(&c.n, -1)
+
+	return handler(ctx, req)
+}
+
+func (c *requestCounter) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	atomic.AddInt64(&c.n, 1)
+	defer atomic.AddInt64(&c.n, -1)
+
+	return handler(srv, ss)
+}
+
+// startupGate rejects calls other than public ones with Unavailable until it is opened
+type startupGate struct {
+	opened int32
+}
+
+func (g *startupGate) open() {
+	atomic.StoreInt32(&g.opened, 1)
+}
+
+func (g *startupGate) check(method string) error {
+	if atomic.LoadInt32(&g.opened) == 1 {
+		return nil
+	}
+
+	for _, public := range publicMethods {
+		if method == public {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unavailable, "Server is starting")
+}
+
+func (g *startupGate) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.check(info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (g *startupGate) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := g.check(info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// serveGateway serves the HTTP/JSON API until the server is shut down.
+// It uses TLS if the server has a TLS config
+func serveGateway(srv *http.Server, l net.Listener) {
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(l, "", "")
+	} else {
+		err = srv.Serve(l)
+	}
+
+	if err != http.ErrServerClosed {
+		log.Fatalf("Couldn't serve the HTTP gateway: %v", err)
+	}
+}
+
+// serveMetrics exposes prometheus metrics over http
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
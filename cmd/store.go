@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/boltdb/bolt"
+	_ "modernc.org/sqlite"
+)
+
+// memoryPath is a DB_PATH which keeps questions in memory instead of a file
+const memoryPath = ":memory:"
+
+// Storage drivers selected by DB_DRIVER
+const (
+	boltDriver   = "bolt"
+	sqliteDriver = "sqlite"
+)
+
+// openStore opens the store configured by DB_DRIVER and DB_PATH.
+// The bolt database is nil unless the store keeps questions in bolt.
+// The returned func closes the database
+func openStore(lockTimeout time.Duration) (question.Store, *bolt.DB, func() error, error) {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = boltDriver
+	}
+
+	return openDriver(driver, os.Getenv("DB_PATH"), lockTimeout)
+}
+
+// openDriver opens the store of the driver at the path
+func openDriver(driver, path string, lockTimeout time.Duration) (question.Store, *bolt.DB, func() error, error) {
+	switch driver {
+	case boltDriver:
+		if path == memoryPath {
+			return question.NewMemoryStore(), nil, func() error { return nil }, nil
+		}
+
+		db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+		if err == bolt.ErrTimeout {
+			return nil, nil, nil, fmt.Errorf("Database file (%s) is locked, is another server running?", path)
+		}
+
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Couldn't load database file (%s): %s", path, err)
+		}
+
+		return question.NewStorage(db), db, closeDB(db.Close), nil
+	case sqliteDriver:
+		db, err := openSQLite(path, lockTimeout)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Couldn't load database file (%s): %s", path, err)
+		}
+
+		return question.NewSQLiteStore(db), nil, closeDB(db.Close), nil
+	default:
+		return nil, nil, nil, fmt.Errorf("Unknown DB_DRIVER %q, use %s or %s", driver, boltDriver, sqliteDriver)
+	}
+}
+
+// openSQLite opens a SQLite database. Writers wait for each other up to
+// lockTimeout. An in-memory database lives as long as its only connection
+func openSQLite(path string, lockTimeout time.Duration) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", lockTimeout.Milliseconds()))
+	params.Add("_txlock", "immediate")
+
+	if path != memoryPath {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sql.Open(sqliteDriver, "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if path == memoryPath {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// closeDB wraps a close func of a database with a friendly error
+func closeDB(close func() error) func() error {
+	return func() error {
+		if err := close(); err != nil {
+			return fmt.Errorf("Couldn't close database file: %v", err)
+		}
+
+		return nil
+	}
+}
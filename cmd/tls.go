@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// serverTLSConfig returns a TLS config of the grpc server and the HTTP gateway
+// for TLS_CERT and TLS_KEY or nil if they aren't set.
+// If TLS_CA is set, clients must present a certificate signed by it.
+// TLS_CLIENT_CA replaces TLS_CA when client certificates are signed by another CA
+func serverTLSConfig() (*tls.Config, error) {
+	certFile, keyFile := os.Getenv("TLS_CERT"), os.Getenv("TLS_KEY")
+	caName := "TLS_CLIENT_CA"
+	if os.Getenv(caName) == "" {
+		caName = "TLS_CA"
+	}
+
+	caFile := os.Getenv(caName)
+	if certFile == "" && keyFile == "" {
+		if caFile != "" {
+			return nil, fmt.Errorf("%s requires TLS_CERT and TLS_KEY", caName)
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't load server certificate: %v", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		config.ClientCAs, err = loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// clientCredentials returns a grpc dial option for the client
+func clientCredentials() (grpc.DialOption, error) {
+	config, err := clientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		return grpc.WithInsecure(), nil
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+// clientTLSConfig returns a TLS config of the client or nil if TLS isn't used.
+// TLS is used when any of TLS_CA, TLS_CLIENT_CERT or TLS_SERVER_NAME is set:
+// TLS_CA verifies the server instead of the system roots,
+// TLS_CLIENT_CERT and TLS_CLIENT_KEY are presented to the server
+func clientTLSConfig() (*tls.Config, error) {
+	caFile, serverName := os.Getenv("TLS_CA"), os.Getenv("TLS_SERVER_NAME")
+	certFile, keyFile := os.Getenv("TLS_CLIENT_CERT"), os.Getenv("TLS_CLIENT_KEY")
+	if caFile == "" && certFile == "" && serverName == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		var err error
+		config.RootCAs, err = loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// loadCertPool reads PEM encoded CA certificates
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read CA certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("No certificates found in %s", file)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/almostmoore/gbquestion/utils"
+)
+
+// certsDir generates certificates for localhost into a temporary directory
+func certsDir(t *testing.T) string {
+	dir := t.TempDir()
+	if err := utils.GenerateCertificates(dir, []string{"localhost"}); err != nil {
+		t.Fatalf("Couldn't generate certificates: %v", err)
+	}
+
+	return dir
+}
+
+// clearTLSEnv unsets all TLS variables for the test
+func clearTLSEnv(t *testing.T) {
+	for _, name := range []string{"TLS_CERT", "TLS_KEY", "TLS_CA", "TLS_CLIENT_CA", "TLS_CLIENT_CERT", "TLS_CLIENT_KEY", "TLS_SERVER_NAME"} {
+		t.Setenv(name, "")
+	}
+}
+
+// handshake connects the client to the server over loopback
+// and returns the errors of both sides
+func handshake(t *testing.T, server, client *tls.Config) (serverErr, clientErr error) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		t.Fatalf("Couldn't start listening: %v", err)
+	}
+	defer l.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		// TLS 1.3 reports a rejected client certificate on the first read
+		_, err = conn.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", l.Addr().String(), client)
+	if err == nil {
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err = conn.Write([]byte{1}); err == nil {
+			// The server closes the connection after the first byte or on a failure
+			_, err = conn.Read(make([]byte, 1))
+			if err == io.EOF {
+				err = nil
+			}
+		}
+		conn.Close()
+	}
+
+	return <-done, err
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	dir := certsDir(t)
+
+	clearTLSEnv(t)
+	if config, err := serverTLSConfig(); config != nil || err != nil {
+		t.Errorf("serverTLSConfig() without certificates = %v, %v, want nil", config, err)
+	}
+
+	for _, name := range []string{"TLS_CA", "TLS_CLIENT_CA"} {
+		clearTLSEnv(t)
+		t.Setenv(name, filepath.Join(dir, "ca.pem"))
+		if _, err := serverTLSConfig(); err == nil {
+			t.Errorf("serverTLSConfig() with %s only succeeded, want an error", name)
+		}
+	}
+
+	clearTLSEnv(t)
+	t.Setenv("TLS_CERT", filepath.Join(dir, "server.pem"))
+	t.Setenv("TLS_KEY", filepath.Join(dir, "server-key.pem"))
+
+	config, err := serverTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.ClientAuth != tls.NoClientCert {
+		t.Errorf("the server verifies clients without a CA, ClientAuth = %v", config.ClientAuth)
+	}
+
+	for _, name := range []string{"TLS_CA", "TLS_CLIENT_CA"} {
+		t.Setenv(name, filepath.Join(dir, "ca.pem"))
+		config, err = serverTLSConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if config.ClientAuth != tls.RequireAndVerifyClientCert || config.ClientCAs == nil {
+			t.Errorf("%s didn't make the server verify clients, ClientAuth = %v", name, config.ClientAuth)
+		}
+	}
+
+	t.Setenv("TLS_CA", filepath.Join(dir, "missing.pem"))
+	if _, err := serverTLSConfig(); err != nil {
+		t.Errorf("serverTLSConfig() read TLS_CA while TLS_CLIENT_CA is set: %v", err)
+	}
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	clearTLSEnv(t)
+	if config, err := clientTLSConfig(); config != nil || err != nil {
+		t.Errorf("clientTLSConfig() without variables = %v, %v, want nil", config, err)
+	}
+
+	t.Setenv("TLS_SERVER_NAME", "localhost")
+	config, err := clientTLSConfig()
+	if err != nil || config == nil || config.ServerName != "localhost" || config.RootCAs != nil {
+		t.Errorf("clientTLSConfig() with TLS_SERVER_NAME = %v, %v, want system roots", config, err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := certsDir(t)
+
+	clearTLSEnv(t)
+	t.Setenv("TLS_CERT", filepath.Join(dir, "server.pem"))
+	t.Setenv("TLS_KEY", filepath.Join(dir, "server-key.pem"))
+	t.Setenv("TLS_CA", filepath.Join(dir, "ca.pem"))
+	t.Setenv("TLS_SERVER_NAME", "localhost")
+
+	server, err := serverTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anonymous, err := clientTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if serverErr, _ := handshake(t, server, anonymous); serverErr == nil {
+		t.Errorf("the server accepted a client without a certificate")
+	}
+
+	t.Setenv("TLS_CLIENT_CERT", filepath.Join(dir, "client.pem"))
+	t.Setenv("TLS_CLIENT_KEY", filepath.Join(dir, "client-key.pem"))
+
+	authenticated, err := clientTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if serverErr, clientErr := handshake(t, server, authenticated); serverErr != nil || clientErr != nil {
+		t.Errorf("handshake with a client certificate failed: server %v, client %v", serverErr, clientErr)
+	}
+
+	t.Setenv("TLS_CA", "")
+	untrusting, err := clientTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, clientErr := handshake(t, server, untrusting); clientErr == nil {
+		t.Errorf("the client trusted a server signed by an unknown CA")
+	}
+}
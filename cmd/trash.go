@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Show, restore or purge deleted questions",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Show deleted questions",
+	PreRunE: initClient,
+	RunE:    trashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "Move a question from the trash back",
+	PreRunE: initClient,
+	RunE:    trashRestore,
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:     "purge",
+	Short:   "Remove questions from the trash for good",
+	PreRunE: initClient,
+	RunE:    trashPurge,
+}
+
+func trashList(cmd *cobra.Command, args []string) error {
+	list, err := client.Trash(context.Background(), &question.Void{})
+	if err != nil {
+		return fmt.Errorf("Couldn't get deleted questions: %v", err)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Text", "Category", "Version", "Deleted At", "Deleted By"})
+
+	for _, trashed := range list.Questions {
+		table.Append([]string{
+			strconv.FormatUint(trashed.Question.Id, 10),
+			trashed.Question.Text,
+			trashed.Question.Category,
+			strconv.FormatUint(trashed.Question.Version, 10),
+			trashed.DeletedAt.AsTime().Local().Format("2006-01-02 15:04:05"),
+			trashed.DeletedBy,
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+func trashRestore(cmd *cobra.Command, args []string) error {
+	req := &question.IdRequest{}
+	req.Id, _ = cmd.Flags().GetUint64("id")
+
+	q, err := client.Restore(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Couldn't restore question %d: %v", req.Id, err)
+	}
+
+	renderQuestions([]*question.Question{q})
+	return nil
+}
+
+func trashPurge(cmd *cobra.Command, args []string) error {
+	req := &question.PurgeRequest{}
+	req.Id, _ = cmd.Flags().GetUint64("id")
+	olderThan, _ := cmd.Flags().GetDuration("older-than")
+	all, _ := cmd.Flags().GetBool("all")
+
+	switch {
+	case req.Id != 0 && (olderThan != 0 || all), olderThan != 0 && all:
+		return fmt.Errorf("Only one of --id, --older-than and --all can be set")
+	case olderThan != 0:
+		req.DeletedBefore = timestamppb.New(time.Now().Add(-olderThan))
+	case all:
+		req.DeletedBefore = timestamppb.Now()
+	case req.Id == 0:
+		return fmt.Errorf("One of --id, --older-than and --all must be set")
+	}
+
+	action := fmt.Sprintf("Purge question %d for good", req.Id)
+	if req.Id == 0 {
+		action = fmt.Sprintf("Purge all questions deleted before %s for good", req.DeletedBefore.AsTime().Local().Format("2006-01-02 15:04:05"))
+	}
+
+	if !confirm(cmd, action) {
+		fmt.Println("Nothing was purged")
+		return nil
+	}
+
+	result, err := client.Purge(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Couldn't purge the trash: %v", err)
+	}
+
+	fmt.Printf("Purged %d questions\n", result.Questions)
+	return nil
+}
+
+func init() {
+	trashRestoreCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+
+	trashPurgeCmd.Flags().Uint64P("id", "", 0, "ID of the question")
+	trashPurgeCmd.Flags().Duration("older-than", 0, "Purge questions deleted earlier than this ago, e.g. 720h")
+	trashPurgeCmd.Flags().Bool("all", false, "Purge all deleted questions")
+	trashPurgeCmd.Flags().BoolP("yes", "y", false, "Purge without confirmation")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+}
@@ -0,0 +1,352 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	// maxBodySize limits a size of request bodies
+	maxBodySize = 1 << 20
+
+	// defaultLimit is a page size of GET /questions without the limit parameter
+	defaultLimit = 100
+
+	methodPrefix = "/question.Questions/"
+)
+
+// errBodyTooLarge is returned for bodies over maxBodySize and written as 413
+var errBodyTooLarge = status.Errorf(codes.InvalidArgument, "Body is larger than %d bytes", maxBodySize)
+
+// Gateway serves an HTTP/JSON API on top of the questions service.
+// Calls go through the same interceptors as grpc ones
+type Gateway struct {
+	service     question.QuestionsServer
+	interceptor grpc.UnaryServerInterceptor
+	marshaler   *jsonpb.Marshaler
+}
+
+// New creates a gateway for the service, interceptors are called in the given order
+func New(service question.QuestionsServer, interceptors ...grpc.UnaryServerInterceptor) *Gateway {
+	return &Gateway{
+		service:     service,
+		interceptor: chain(interceptors),
+		marshaler:   &jsonpb.Marshaler{EmitDefaults: true},
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	switch {
+	case path == "/openapi.json" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	case path == "/questions":
+		g.collection(w, r)
+	case strings.HasPrefix(path, "/questions/"):
+		id, err := strconv.ParseUint(strings.TrimPrefix(path, "/questions/"), 10, 64)
+		if err != nil {
+			g.writeError(w, status.Error(codes.InvalidArgument, "ID of a question must be a number"))
+			return
+		}
+		g.item(w, r, id)
+	default:
+		g.writeError(w, status.Error(codes.NotFound, "Unknown path"))
+	}
+}
+
+// collection handles GET and POST /questions
+func (g *Gateway) collection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		filter, err := parseFilter(r)
+		if err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		g.call(w, r, "List", filter, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.service.List(ctx, req.(*question.Filter))
+		})
+	case http.MethodPost:
+		q := &question.Question{}
+		if err := g.readBody(w, r, q); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		q.Id = 0
+		g.call(w, r, "Put", q, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.service.Put(ctx, req.(*question.Question))
+		})
+	default:
+		g.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// item handles GET, PUT and DELETE /questions/{id}
+func (g *Gateway) item(w http.ResponseWriter, r *http.Request, id uint64) {
+	switch r.Method {
+	case http.MethodGet:
+		g.call(w, r, "Get", &question.IdRequest{Id: id}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.service.Get(ctx, req.(*question.IdRequest))
+		})
+	case http.MethodPut:
+		q := &question.Question{}
+		if err := g.readBody(w, r, q); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		q.Id = id
+		g.call(w, r, "Put", q, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.service.Put(ctx, req.(*question.Question))
+		})
+	case http.MethodDelete:
+		g.call(w, r, "Delete", &question.IdRequest{Id: id}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.service.Delete(ctx, req.(*question.IdRequest))
+		})
+	default:
+		g.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// call runs a service method through the interceptors and writes its response
+func (g *Gateway) call(w http.ResponseWriter, r *http.Request, method string, req interface{}, handler grpc.UnaryHandler) {
+	info := &grpc.UnaryServerInfo{
+		Server:     g.service,
+		FullMethod: methodPrefix + method,
+	}
+
+	ctx := grpc.NewContextWithServerTransportStream(incomingContext(r), &headerStream{method: info.FullMethod, header: w.Header()})
+
+	resp, err := g.interceptor(ctx, req, info, handler)
+	if err != nil {
+		g.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := g.marshaler.Marshal(w, resp.(proto.Message)); err != nil {
+		g.writeError(w, err)
+	}
+}
+
+// incomingContext passes the authorization and duplicates headers
+// and the client address the same way grpc does
+func incomingContext(r *http.Request) context.Context {
+	ctx := r.Context()
+
+	md := metadata.MD{}
+	for key, header := range map[string]string{"authorization": "Authorization", question.DuplicatesKey: "X-Duplicates"} {
+		if value := r.Header.Get(header); value != "" {
+			md.Set(key, value)
+		}
+	}
+
+	if len(md) > 0 {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+
+	return ctx
+}
+
+// headerStream writes grpc headers and trailers set by service methods
+// as HTTP headers of the response
+type headerStream struct {
+	method string
+	header http.Header
+}
+
+func (s *headerStream) Method() string {
+	return s.method
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	for key, values := range md {
+		for _, value := range values {
+			s.header.Add(key, value)
+		}
+	}
+
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *headerStream) SetTrailer(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+// readBody decodes a JSON request body into the message
+func (g *Gateway) readBody(w http.ResponseWriter, r *http.Request, m proto.Message) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errBodyTooLarge
+	}
+
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Couldn't read a body: %v", err)
+	}
+
+	if err := jsonpb.Unmarshal(bytes.NewReader(body), m); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Couldn't decode a body: %v", err)
+	}
+
+	return nil
+}
+
+// parseFilter reads a filter from query parameters.
+// isActive is true and limit is 100 unless they are set
+func parseFilter(r *http.Request) (*question.Filter, error) {
+	query := r.URL.Query()
+	filter := &question.Filter{
+		IsActive:   true,
+		Limit:      defaultLimit,
+		PageToken:  query.Get("pageToken"),
+		Categories: query["category"],
+		Tags:       query["tag"],
+	}
+
+	var err error
+	parseBool := func(name string, v *bool) {
+		if s := query.Get(name); s != "" && err == nil {
+			if *v, err = strconv.ParseBool(s); err != nil {
+				err = status.Errorf(codes.InvalidArgument, "Invalid %s %q", name, s)
+			}
+		}
+	}
+	parseInt := func(name string, v *int32) {
+		if s := query.Get(name); s != "" && err == nil {
+			n, parseErr := strconv.ParseInt(s, 10, 32)
+			if parseErr != nil {
+				err = status.Errorf(codes.InvalidArgument, "Invalid %s %q", name, s)
+			}
+			*v = int32(n)
+		}
+	}
+
+	parseBool("isActive", &filter.IsActive)
+	parseBool("allTags", &filter.AllTags)
+	if s := query.Get("isGood"); s != "" && err == nil {
+		isGood, parseErr := strconv.ParseBool(s)
+		if parseErr != nil {
+			err = status.Errorf(codes.InvalidArgument, "Invalid isGood %q", s)
+		}
+		filter.IsGood = wrapperspb.Bool(isGood)
+	}
+	parseInt("limit", &filter.Limit)
+	parseInt("offset", &filter.Offset)
+
+	for _, s := range query["ignoreId"] {
+		id, parseErr := strconv.ParseUint(s, 10, 64)
+		if parseErr != nil && err == nil {
+			err = status.Errorf(codes.InvalidArgument, "Invalid ignoreId %q", s)
+		}
+		filter.IgnoreIds = append(filter.IgnoreIds, id)
+	}
+
+	return filter, err
+}
+
+// errorBody is a JSON body of failed requests
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError writes a grpc status as an HTTP error
+func (g *Gateway) writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+
+	code := httpStatus(st.Code())
+	if err == errBodyTooLarge {
+		code = http.StatusRequestEntityTooLarge
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorBody{Code: st.Code().String(), Message: st.Message()})
+}
+
+// methodNotAllowed writes an error for an HTTP method the path doesn't support
+func (g *Gateway) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	json.NewEncoder(w).Encode(errorBody{Code: "MethodNotAllowed", Message: "Method is not allowed"})
+}
+
+// httpStatus maps grpc codes to HTTP status codes
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Canceled:
+		return 499
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// chain combines interceptors into one, the first one is the outermost
+func chain(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
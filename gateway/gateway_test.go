@@ -0,0 +1,222 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// newGateway creates a gateway of a service on top of an empty MemoryStore
+func newGateway(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) *Gateway {
+	s := question.NewMemoryStore()
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	return New(question.NewRPCService(s), interceptors...)
+}
+
+// serve sends a request to the gateway and returns the recorded response
+func serve(g *Gateway, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	return w
+}
+
+// errorCode returns the grpc code written in the error body
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body errorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Couldn't decode an error body %q: %v", w.Body.String(), err)
+	}
+
+	return body.Code
+}
+
+func TestRoutes(t *testing.T) {
+	g := newGateway(t)
+
+	w := serve(g, http.MethodPost, "/questions", `{"text": "first", "isActive": true}`, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"1"`) {
+		t.Fatalf("POST /questions = %d %s, want question 1", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		contains             string
+	}{
+		{http.MethodGet, "/questions/1", "", http.StatusOK, "first"},
+		{http.MethodGet, "/questions/1/", "", http.StatusOK, "first"},
+		{http.MethodGet, "/questions", "", http.StatusOK, "first"},
+		{http.MethodGet, "/questions?isActive=false", "", http.StatusOK, `"questions":[]`},
+		{http.MethodPut, "/questions/1", `{"text": "second", "version": "1"}`, http.StatusOK, "second"},
+		{http.MethodGet, "/openapi.json", "", http.StatusOK, "openapi"},
+		{http.MethodGet, "/questions/abc", "", http.StatusBadRequest, "InvalidArgument"},
+		{http.MethodGet, "/unknown", "", http.StatusNotFound, "NotFound"},
+		{http.MethodDelete, "/questions/1", "", http.StatusOK, ""},
+		{http.MethodGet, "/questions/1", "", http.StatusNotFound, "NotFound"},
+	}
+
+	for _, test := range tests {
+		w := serve(g, test.method, test.target, test.body, nil)
+		if w.Code != test.status || !strings.Contains(w.Body.String(), test.contains) {
+			t.Errorf("%s %s = %d %s, want %d with %q", test.method, test.target, w.Code, w.Body, test.status, test.contains)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	g := newGateway(t)
+
+	tests := []struct {
+		method, target, allow string
+	}{
+		{http.MethodDelete, "/questions", "GET, POST"},
+		{http.MethodPost, "/questions/1", "GET, PUT, DELETE"},
+	}
+
+	for _, test := range tests {
+		w := serve(g, test.method, test.target, "", nil)
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != test.allow {
+			t.Errorf("%s %s = %d with Allow %q, want 405 with %q", test.method, test.target, w.Code, w.Header().Get("Allow"), test.allow)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code   codes.Code
+		status int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Aborted, http.StatusConflict},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.Internal, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := httpStatus(test.code); got != test.status {
+			t.Errorf("httpStatus(%s) = %d, want %d", test.code, got, test.status)
+		}
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	g := newGateway(t)
+
+	serve(g, http.MethodPost, "/questions", `{"text": "first"}`, nil)
+	serve(g, http.MethodPut, "/questions/1", `{"text": "second", "version": "1"}`, nil)
+
+	w := serve(g, http.MethodPut, "/questions/1", `{"text": "stale", "version": "1"}`, nil)
+	if w.Code != http.StatusConflict || errorCode(t, w) != "Aborted" {
+		t.Errorf("PUT with a stale version = %d %s, want 409 Aborted", w.Code, w.Body)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, query := range []string{"isActive=maybe", "allTags=1x", "isGood=yes", "limit=ten", "offset=99999999999", "ignoreId=-1"} {
+		r := httptest.NewRequest(http.MethodGet, "/questions?"+query, nil)
+		if filter, err := parseFilter(r); err == nil {
+			t.Errorf("parseFilter(%s) = %v, want an error", query, filter)
+		}
+
+		w := serve(newGateway(t), http.MethodGet, "/questions?"+query, "", nil)
+		if w.Code != http.StatusBadRequest || errorCode(t, w) != "InvalidArgument" {
+			t.Errorf("GET /questions?%s = %d %s, want 400 InvalidArgument", query, w.Code, w.Body)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/questions?isActive=false&isGood=true&limit=5&offset=2&ignoreId=3&ignoreId=4&tag=a&tag=b&allTags=true", nil)
+	filter, err := parseFilter(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.IsActive || !filter.IsGood.GetValue() || filter.Limit != 5 || filter.Offset != 2 ||
+		len(filter.IgnoreIds) != 2 || len(filter.Tags) != 2 || !filter.AllTags {
+		t.Errorf("parseFilter() = %v, want all parameters", filter)
+	}
+
+	filter, err = parseFilter(httptest.NewRequest(http.MethodGet, "/questions", nil))
+	if err != nil || !filter.IsActive || filter.Limit != defaultLimit || filter.IsGood != nil {
+		t.Errorf("parseFilter() without parameters = %v, %v, want active questions of any goodness", filter, err)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	g := newGateway(t)
+
+	body := `{"text": "` + strings.Repeat("a", maxBodySize) + `"}`
+	if w := serve(g, http.MethodPost, "/questions", body, nil); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST of %d bytes = %d %s, want 413", len(body), w.Code, w.Body)
+	}
+
+	if w := serve(g, http.MethodPost, "/questions", `{"text": `, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("POST of a malformed body = %d, want 400", w.Code)
+	}
+}
+
+func TestDuplicatesHeader(t *testing.T) {
+	g := newGateway(t)
+
+	tests := []struct {
+		mode   string
+		status int
+	}{
+		{"", http.StatusOK},
+		{question.DuplicatesAllow, http.StatusOK},
+		// MemoryStore doesn't look for duplicates, so it can't reject them
+		{question.DuplicatesReject, http.StatusNotImplemented},
+		{"unknown", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		if test.mode != "" {
+			header.Set("X-Duplicates", test.mode)
+		}
+
+		if w := serve(g, http.MethodPost, "/questions", `{"text": "q"}`, header); w.Code != test.status {
+			t.Errorf("POST with X-Duplicates %q = %d %s, want %d", test.mode, w.Code, w.Body, test.status)
+		}
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+" "+info.FullMethod)
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+" done")
+			return resp, err
+		}
+	}
+
+	g := newGateway(t, interceptor("outer"), interceptor("inner"))
+	serve(g, http.MethodGet, "/questions", "", nil)
+
+	want := "outer /question.Questions/List, inner /question.Questions/List, inner done, outer done"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("interceptors were called as %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/golang/protobuf/descriptor"
+	descpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// openAPI is an OpenAPI 3 document of the gateway.
+// Schemas are generated from the protobuf descriptors of the messages
+var openAPI = mustMarshal(buildOpenAPI())
+
+type object map[string]interface{}
+
+func buildOpenAPI() object {
+	schemas := object{
+		"Error": object{
+			"type": "object",
+			"properties": object{
+				"code":    object{"type": "string"},
+				"message": object{"type": "string"},
+			},
+		},
+	}
+	addSchema(schemas, &question.QuestionList{})
+	addSchema(schemas, &question.Void{})
+
+	idParam := object{
+		"name": "id", "in": "path", "required": true,
+		"schema": object{"type": "integer", "format": "uint64"},
+	}
+	query := func(name, typ, description string, repeated bool) object {
+		schema := object{"type": typ}
+		if repeated {
+			schema = object{"type": "array", "items": schema}
+		}
+		return object{"name": name, "in": "query", "description": description, "schema": schema}
+	}
+
+	duplicatesParam := object{
+		"name": "X-Duplicates", "in": "header",
+		"description": "What to do with similar questions: warn lists them in X-Similar-Questions, reject fails, allow skips the check",
+		"schema":      object{"type": "string", "enum": []string{question.DuplicatesWarn, question.DuplicatesReject, question.DuplicatesAllow}},
+	}
+
+	questionBody := object{
+		"required": true,
+		"content":  object{"application/json": object{"schema": ref("Question")}},
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "gbquestion",
+			"version": "1",
+		},
+		"paths": object{
+			"/questions": object{
+				"get": operation("List", "Filter questions", "QuestionList", []object{
+					query("isActive", "boolean", "Active or disabled questions, true by default", false),
+					query("isGood", "boolean", "Good or bad questions, both by default", false),
+					query("limit", "integer", "Page size, 100 by default", false),
+					query("offset", "integer", "Number of questions to skip", false),
+					query("pageToken", "string", "nextPageToken of the previous page", false),
+					query("ignoreId", "integer", "IDs of questions to skip", true),
+					query("category", "string", "Categories, any of them matches", true),
+					query("tag", "string", "Tags, any of them matches unless allTags is set", true),
+					query("allTags", "boolean", "Require all of the tags", false),
+				}, nil),
+				"post": operation("Create", "Create a question", "Question", []object{duplicatesParam}, questionBody),
+			},
+			"/questions/{id}": object{
+				"get":    operation("Get", "Get a question", "Question", []object{idParam}, nil),
+				"put":    operation("Put", "Create or replace a question", "Question", []object{idParam, duplicatesParam}, questionBody),
+				"delete": operation("Delete", "Delete a question", "Void", []object{idParam}, nil),
+			},
+		},
+		"components": object{
+			"schemas": schemas,
+		},
+	}
+}
+
+func operation(id, summary, response string, params []object, body object) object {
+	op := object{
+		"operationId": id,
+		"summary":     summary,
+		"responses": object{
+			"200": object{
+				"description": "OK",
+				"content":     object{"application/json": object{"schema": ref(response)}},
+			},
+			"default": object{
+				"description": "Error",
+				"content":     object{"application/json": object{"schema": ref("Error")}},
+			},
+		},
+	}
+
+	if params != nil {
+		op["parameters"] = params
+	}
+
+	if body != nil {
+		op["requestBody"] = body
+	}
+
+	return op
+}
+
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// addSchema adds schemas of the message and the messages and enums it refers to
+func addSchema(schemas object, m descriptor.Message) {
+	file, msg := descriptor.ForMessage(m)
+	addMessageSchema(schemas, file, msg)
+}
+
+func addMessageSchema(schemas object, file *descpb.FileDescriptorProto, msg *descpb.DescriptorProto) {
+	if _, ok := schemas[msg.GetName()]; ok {
+		return
+	}
+
+	properties := object{}
+	schemas[msg.GetName()] = object{"type": "object", "properties": properties}
+
+	for _, field := range msg.Field {
+		schema := fieldSchema(schemas, file, msg, field)
+		if field.GetLabel() == descpb.FieldDescriptorProto_LABEL_REPEATED {
+			schema = object{"type": "array", "items": schema}
+		}
+
+		properties[field.GetJsonName()] = schema
+	}
+}
+
+// fieldSchema returns a schema of the field value according to the protobuf JSON mapping
+func fieldSchema(schemas object, file *descpb.FileDescriptorProto, msg *descpb.DescriptorProto, field *descpb.FieldDescriptorProto) object {
+	switch field.GetType() {
+	case descpb.FieldDescriptorProto_TYPE_BOOL:
+		return object{"type": "boolean"}
+	case descpb.FieldDescriptorProto_TYPE_STRING:
+		return object{"type": "string"}
+	case descpb.FieldDescriptorProto_TYPE_BYTES:
+		return object{"type": "string", "format": "byte"}
+	case descpb.FieldDescriptorProto_TYPE_INT32, descpb.FieldDescriptorProto_TYPE_SINT32, descpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return object{"type": "integer", "format": "int32"}
+	case descpb.FieldDescriptorProto_TYPE_UINT32, descpb.FieldDescriptorProto_TYPE_FIXED32:
+		return object{"type": "integer", "format": "uint32"}
+	case descpb.FieldDescriptorProto_TYPE_INT64, descpb.FieldDescriptorProto_TYPE_SINT64, descpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return object{"type": "string", "format": "int64"}
+	case descpb.FieldDescriptorProto_TYPE_UINT64, descpb.FieldDescriptorProto_TYPE_FIXED64:
+		return object{"type": "string", "format": "uint64"}
+	case descpb.FieldDescriptorProto_TYPE_FLOAT, descpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return object{"type": "number"}
+	case descpb.FieldDescriptorProto_TYPE_ENUM:
+		if enum := findEnum(file, msg, field.GetTypeName()); enum != nil {
+			var values []string
+			for _, v := range enum.Value {
+				values = append(values, v.GetName())
+			}
+			return object{"type": "string", "enum": values}
+		}
+		return object{"type": "string"}
+	case descpb.FieldDescriptorProto_TYPE_MESSAGE:
+		if nested := findMessage(file, field.GetTypeName()); nested != nil {
+			addMessageSchema(schemas, file, nested)
+			return ref(nested.GetName())
+		}
+		return object{"type": "object"}
+	default:
+		return object{}
+	}
+}
+
+// findMessage looks up a top-level message of the file by its full type name
+func findMessage(file *descpb.FileDescriptorProto, typeName string) *descpb.DescriptorProto {
+	name := strings.TrimPrefix(typeName, "."+file.GetPackage()+".")
+	for _, msg := range file.MessageType {
+		if msg.GetName() == name {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+// findEnum looks up an enum nested into the message or a top-level one
+func findEnum(file *descpb.FileDescriptorProto, msg *descpb.DescriptorProto, typeName string) *descpb.EnumDescriptorProto {
+	name := typeName[strings.LastIndex(typeName, ".")+1:]
+	for _, enum := range msg.EnumType {
+		if enum.GetName() == name {
+			return enum
+		}
+	}
+
+	for _, enum := range file.EnumType {
+		if enum.GetName() == name {
+			return enum
+		}
+	}
+
+	return nil
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
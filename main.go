@@ -1,7 +1,13 @@
 package main
 
-import "github.com/almostmoore/gbquestion/cmd"
+import (
+	"os"
+
+	"github.com/almostmoore/gbquestion/cmd"
+)
 
 func main() {
-	cmd.RootCmd.Execute()
+	if err := cmd.RootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
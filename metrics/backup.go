@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	backups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gbquestion_backups_total",
+		Help: "Number of scheduled backups by result.",
+	}, []string{"result"})
+
+	backupDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gbquestion_backup_duration_seconds",
+		Help: "Duration of the last scheduled backup.",
+	})
+
+	backupSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gbquestion_backup_size_bytes",
+		Help: "Size of the last successful scheduled backup.",
+	})
+
+	backupLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gbquestion_backup_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful scheduled backup.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(backups, backupDuration, backupSize, backupLastSuccess)
+}
+
+// ObserveBackup records an outcome of a scheduled backup
+func ObserveBackup(start time.Time, size int64, err error) {
+	backupDuration.Set(time.Since(start).Seconds())
+
+	if err != nil {
+		backups.WithLabelValues("failure").Inc()
+		return
+	}
+
+	backups.WithLabelValues("success").Inc()
+	backupSize.Set(float64(size))
+	backupLastSuccess.Set(float64(time.Now().Unix()))
+}
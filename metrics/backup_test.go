@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveBackup(t *testing.T) {
+	success := testutil.ToFloat64(backups.WithLabelValues("success"))
+	failure := testutil.ToFloat64(backups.WithLabelValues("failure"))
+
+	ObserveBackup(time.Now(), 42, nil)
+	ObserveBackup(time.Now(), 0, errors.New("disk is full"))
+
+	if n := testutil.ToFloat64(backups.WithLabelValues("success")) - success; n != 1 {
+		t.Errorf("successful backups grew by %v, want 1", n)
+	}
+
+	if n := testutil.ToFloat64(backups.WithLabelValues("failure")) - failure; n != 1 {
+		t.Errorf("failed backups grew by %v, want 1", n)
+	}
+
+	if size := testutil.ToFloat64(backupSize); size != 42 {
+		t.Errorf("backup size = %v after a failure, want 42 of the last successful one", size)
+	}
+}
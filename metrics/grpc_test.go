@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// observations returns the number of latency observations of the method
+func observations(t *testing.T, method string) uint64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	if err := latency.WithLabelValues(method).(prometheus.Histogram).Write(m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/test.Service/Unary"
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	for _, handler := range []grpc.UnaryHandler{ok, ok, notFound} {
+		interceptor(context.Background(), "req", info, handler)
+	}
+
+	if n := testutil.ToFloat64(requests.WithLabelValues(method, "OK")); n != 2 {
+		t.Errorf("%s requests with OK = %v, want 2", method, n)
+	}
+
+	if n := testutil.ToFloat64(requests.WithLabelValues(method, "NotFound")); n != 1 {
+		t.Errorf("%s requests with NotFound = %v, want 1", method, n)
+	}
+
+	if n := observations(t, method); n != 3 {
+		t.Errorf("%s latency has %d observations, want 3", method, n)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	const method = "/test.Service/Stream"
+	interceptor := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}
+
+	interceptor(nil, nil, info, func(srv interface{}, ss grpc.ServerStream) error {
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("broken stream")
+	})
+
+	if n := testutil.ToFloat64(requests.WithLabelValues(method, "Unknown")); n != 1 {
+		t.Errorf("%s requests with Unknown = %v, want 1", method, n)
+	}
+
+	m := &dto.Metric{}
+	latency.WithLabelValues(method).(prometheus.Histogram).Write(m)
+	if h := m.GetHistogram(); h.GetSampleCount() != 1 || h.GetSampleSum() < 0.01 {
+		t.Errorf("%s latency = %d observations of %vs, want the whole stream", method, h.GetSampleCount(), h.GetSampleSum())
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"log"
+	"os"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/boltdb/bolt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	questionsDesc = prometheus.NewDesc(
+		"gbquestion_questions",
+		"Number of stored questions by activity.",
+		[]string{"state"}, nil,
+	)
+
+	fileSizeDesc = prometheus.NewDesc(
+		"gbquestion_bolt_file_size_bytes",
+		"Size of the bolt database file.",
+		nil, nil,
+	)
+
+	txDesc = prometheus.NewDesc(
+		"gbquestion_bolt_tx_total",
+		"Number of started read transactions.",
+		nil, nil,
+	)
+
+	openTxDesc = prometheus.NewDesc(
+		"gbquestion_bolt_open_tx",
+		"Number of currently open read transactions.",
+		nil, nil,
+	)
+
+	freePagesDesc = prometheus.NewDesc(
+		"gbquestion_bolt_free_pages",
+		"Number of free pages on the freelist.",
+		nil, nil,
+	)
+
+	pageAllocDesc = prometheus.NewDesc(
+		"gbquestion_bolt_page_alloc_bytes_total",
+		"Total bytes allocated for pages by transactions.",
+		nil, nil,
+	)
+
+	writesDesc = prometheus.NewDesc(
+		"gbquestion_bolt_writes_total",
+		"Number of writes performed by transactions.",
+		nil, nil,
+	)
+
+	writeTimeDesc = prometheus.NewDesc(
+		"gbquestion_bolt_write_seconds_total",
+		"Total time spent writing to disk.",
+		nil, nil,
+	)
+)
+
+// StorageCollector exposes numbers of questions and bolt statistics.
+// Values are read on every scrape
+type StorageCollector struct {
+	storage question.Store
+	db      *bolt.DB
+}
+
+// NewStorageCollector creates a collector for the storage and its database.
+// db is nil for a store which doesn't keep questions in bolt,
+// then only numbers of questions are exposed
+func NewStorageCollector(s question.Store, db *bolt.DB) *StorageCollector {
+	return &StorageCollector{
+		storage: s,
+		db:      db,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *StorageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- questionsDesc
+	ch <- fileSizeDesc
+	ch <- txDesc
+	ch <- openTxDesc
+	ch <- freePagesDesc
+	ch <- pageAllocDesc
+	ch <- writesDesc
+	ch <- writeTimeDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *StorageCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.storage.Count()
+	if err != nil {
+		log.Printf("Couldn't count questions: %v", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(questionsDesc, prometheus.GaugeValue, float64(counts.Active), "active")
+		ch <- prometheus.MustNewConstMetric(questionsDesc, prometheus.GaugeValue, float64(counts.Inactive), "inactive")
+	}
+
+	if c.db == nil {
+		return
+	}
+
+	if info, err := os.Stat(c.db.Path()); err == nil {
+		ch <- prometheus.MustNewConstMetric(fileSizeDesc, prometheus.GaugeValue, float64(info.Size()))
+	}
+
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(txDesc, prometheus.CounterValue, float64(stats.TxN))
+	ch <- prometheus.MustNewConstMetric(openTxDesc, prometheus.GaugeValue, float64(stats.OpenTxN))
+	ch <- prometheus.MustNewConstMetric(freePagesDesc, prometheus.GaugeValue, float64(stats.FreePageN))
+	ch <- prometheus.MustNewConstMetric(pageAllocDesc, prometheus.CounterValue, float64(stats.TxStats.PageAlloc))
+	ch <- prometheus.MustNewConstMetric(writesDesc, prometheus.CounterValue, float64(stats.TxStats.Write))
+	ch <- prometheus.MustNewConstMetric(writeTimeDesc, prometheus.CounterValue, stats.TxStats.WriteTime.Seconds())
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/boltdb/bolt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStorageCollector(t *testing.T) {
+	s := question.NewMemoryStore()
+	for _, q := range []question.Question{{Text: "a", IsActive: true}, {Text: "b", IsActive: true}, {Text: "c"}} {
+		if _, err := s.Put(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `
+# HELP gbquestion_questions Number of stored questions by activity.
+# TYPE gbquestion_questions gauge
+gbquestion_questions{state="active"} 2
+gbquestion_questions{state="inactive"} 1
+`
+	c := NewStorageCollector(s, nil)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Errorf("StorageCollector without bolt: %v", err)
+	}
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "questions.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Both counts, the file size and six statistics of bolt
+	if n := testutil.CollectAndCount(NewStorageCollector(s, db)); n != 9 {
+		t.Errorf("StorageCollector with bolt exposes %d metrics, want 9", n)
+	}
+
+	if problems, err := testutil.CollectAndLint(NewStorageCollector(s, db)); err != nil || len(problems) > 0 {
+		t.Errorf("StorageCollector lint = %v, %v", problems, err)
+	}
+}
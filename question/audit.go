@@ -0,0 +1,295 @@
+package question
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	auditBucketName = []byte("audit")
+	auditIndexName  = []byte("audit_questions")
+)
+
+// The audit log is append-only: audit maps sequence numbers to entries,
+// so they are ordered as they were written, and audit_questions has a key
+// made of a question ID and the sequence number for every entry of the question.
+// Timestamps of entries never go backwards, an entry written while the clock
+// is behind the previous one gets its time, so entries since a time are found
+// with a binary search over sequence numbers. The only exception from appending
+// is a purge: it removes the question from entries of the question, see auditForget
+
+// Page sizes of the audit log
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// Auditor is implemented by stores which keep a log of changes
+type Auditor interface {
+	// AuditLog returns a page of the log, the oldest entries first
+	AuditLog(req *AuditRequest) (*AuditEntryList, error)
+}
+
+var _ Auditor = (*Storage)(nil)
+
+// auditAdd appends a change of a question made by the actor to the audit log.
+// Before is nil for a created question and after is nil for a deleted one
+func auditAdd(tx *bolt.Tx, actor Actor, before, after *Question) error {
+	entry := &AuditEntry{
+		Before: before,
+		After:  after,
+	}
+
+	switch {
+	case before == nil:
+		entry.Kind, entry.QuestionId = AuditEntry_CREATED, after.Id
+	case after == nil:
+		entry.Kind, entry.QuestionId = AuditEntry_DELETED, before.Id
+	default:
+		entry.Kind, entry.QuestionId = AuditEntry_UPDATED, after.Id
+	}
+
+	return auditAppend(tx, actor, entry)
+}
+
+// auditPurge appends a purge of a question made by the actor to the audit log
+func auditPurge(tx *bolt.Tx, actor Actor, id uint64) error {
+	return auditAppend(tx, actor, &AuditEntry{Kind: AuditEntry_PURGED, QuestionId: id})
+}
+
+// auditAppend writes an entry made by the actor with the next sequence number
+func auditAppend(tx *bolt.Tx, actor Actor, entry *AuditEntry) error {
+	b, err := tx.CreateBucketIfNotExists(auditBucketName)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.CreateBucketIfNotExists(auditIndexName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if _, v := b.Cursor().Last(); v != nil {
+		last := &AuditEntry{}
+		if err := proto.Unmarshal(v, last); err != nil {
+			return err
+		}
+
+		if t := last.Timestamp.AsTime(); now.Before(t) {
+			now = t
+		}
+	}
+
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	entry.Timestamp = timestamppb.New(now)
+	entry.Method = actor.Method
+	entry.Caller = actor.Name
+
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(seq)
+	if err := b.Put(key, data); err != nil {
+		return err
+	}
+
+	return idx.Put(concat(utils.Uinttob(entry.QuestionId), key), []byte{})
+}
+
+// auditForget removes the question from all entries of the question,
+// so a purged question doesn't outlive its history in the audit log.
+// The entries keep the time, the RPC, the caller and the question ID
+func auditForget(tx *bolt.Tx, id uint64) error {
+	b, idx := tx.Bucket(auditBucketName), tx.Bucket(auditIndexName)
+	if b == nil || idx == nil {
+		return nil
+	}
+
+	prefix := utils.Uinttob(id)
+	c := idx.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		key := k[len(prefix):]
+
+		entry := &AuditEntry{}
+		if err := proto.Unmarshal(b.Get(key), entry); err != nil {
+			return err
+		}
+
+		if entry.Before == nil && entry.After == nil {
+			continue
+		}
+
+		entry.Before, entry.After = nil, nil
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(key, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AuditLog returns a page of the audit log, the oldest entries first.
+// Entries of req.QuestionId are read from the index
+// and req.Since is found with a seek, so neither scans the whole log
+func (qs *Storage) AuditLog(req *AuditRequest) (*AuditEntryList, error) {
+	limit := int(req.Limit)
+	switch {
+	case limit < 0:
+		return nil, invalidArgument("limit must not be negative")
+	case limit == 0:
+		limit = defaultAuditLimit
+	case limit > maxAuditLimit:
+		limit = maxAuditLimit
+	}
+
+	var after []byte
+	if req.PageToken != "" {
+		var err error
+		after, err = decodeAuditToken(req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Since != nil {
+		if err := req.Since.CheckValid(); err != nil {
+			return nil, invalidArgument("invalid since: %v", err)
+		}
+	}
+
+	list := &AuditEntryList{
+		Entries: make([]*AuditEntry, 0),
+	}
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(auditBucketName)
+		if b == nil {
+			return nil
+		}
+
+		var prefix []byte
+		var c *bolt.Cursor
+
+		if req.QuestionId != 0 {
+			idx := tx.Bucket(auditIndexName)
+			if idx == nil {
+				return nil
+			}
+
+			prefix = utils.Uinttob(req.QuestionId)
+			c = idx.Cursor()
+		} else {
+			c = b.Cursor()
+		}
+
+		var since []byte
+		if req.Since != nil {
+			var err error
+			if since, err = auditSeek(b, req.Since.AsTime()); err != nil {
+				return err
+			}
+		}
+
+		k, _ := c.Seek(concat(prefix, since))
+		if after != nil {
+			start := concat(prefix, after)
+			if k, _ = c.Seek(start); bytes.Equal(k, start) {
+				k, _ = c.Next()
+			}
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			key := k[len(prefix):]
+			if len(list.Entries) == limit {
+				list.NextPageToken = encodePageToken(after)
+				break
+			}
+
+			entry := &AuditEntry{}
+			if err := proto.Unmarshal(b.Get(key), entry); err != nil {
+				return err
+			}
+
+			list.Entries = append(list.Entries, entry)
+			after = concat(key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// auditSeek returns the key of the first entry written at or after t.
+// Timestamps never go backwards, so it is a binary search over sequence numbers,
+// the sequence after the last one is returned if there is no such entry
+func auditSeek(b *bolt.Bucket, t time.Time) ([]byte, error) {
+	c := b.Cursor()
+	lo, hi := uint64(1), b.Sequence()+1
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		k, v := c.Seek(utils.Uinttob(mid))
+		if k == nil {
+			hi = mid
+			continue
+		}
+
+		entry := &AuditEntry{}
+		if err := proto.Unmarshal(v, entry); err != nil {
+			return nil, err
+		}
+
+		if entry.Timestamp.AsTime().Before(t) {
+			lo = binary.BigEndian.Uint64(k) + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return utils.Uinttob(lo), nil
+}
+
+// concat returns a new slice with all the parts
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+
+	return result
+}
+
+// decodeAuditToken returns a key of the audit log encoded into the page token
+func decodeAuditToken(token string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(key) != len(utils.Uinttob(0)) {
+		return nil, invalidArgument("page token %q is malformed", token)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,115 @@
+package question_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// auditLog reads all entries of the request following page tokens
+func auditLog(t *testing.T, s *question.Storage, req *question.AuditRequest) []*question.AuditEntry {
+	t.Helper()
+
+	var entries []*question.AuditEntry
+	for {
+		list, err := s.AuditLog(req)
+		if err != nil {
+			t.Fatalf("AuditLog() failed: %v", err)
+		}
+
+		entries = append(entries, list.Entries...)
+		if list.NextPageToken == "" {
+			return entries
+		}
+
+		req.PageToken = list.NextPageToken
+	}
+}
+
+// describe returns kinds and question IDs of the entries
+func describe(entries []*question.AuditEntry) string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, fmt.Sprintf("%s %d", e.Kind, e.QuestionId))
+	}
+
+	return fmt.Sprint(result)
+}
+
+func TestAuditLogKinds(t *testing.T) {
+	s := newBoltStorage(t)
+
+	change, err := s.Put(question.Question{Text: "one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := change.After.Id
+
+	if _, err := s.Update(&question.Question{Id: id, Text: "two"}, []string{"text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Restore(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Purge(&question.PurgeRequest{Id: id}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := describe(auditLog(t, s, &question.AuditRequest{QuestionId: id}))
+	want := fmt.Sprintf("[CREATED %[1]d UPDATED %[1]d DELETED %[1]d CREATED %[1]d DELETED %[1]d PURGED %[1]d]", id)
+	if got != want {
+		t.Errorf("AuditLog() = %s, want %s", got, want)
+	}
+}
+
+func TestAuditLogPages(t *testing.T) {
+	s := newBoltStorage(t)
+
+	var times []time.Time
+	for i := 0; i < 7; i++ {
+		if _, err := s.Put(question.Question{Text: fmt.Sprintf("q%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+
+		times = append(times, time.Now())
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if got := auditLog(t, s, &question.AuditRequest{Limit: 2}); describe(got) != "[CREATED 1 CREATED 2 CREATED 3 CREATED 4 CREATED 5 CREATED 6 CREATED 7]" {
+		t.Errorf("AuditLog() pages = %s, want all 7 entries in order", describe(got))
+	}
+
+	since := timestamppb.New(times[3])
+	if got := auditLog(t, s, &question.AuditRequest{Since: since, Limit: 2}); describe(got) != "[CREATED 5 CREATED 6 CREATED 7]" {
+		t.Errorf("AuditLog(since the 4th entry) = %s, want entries of questions 5, 6 and 7", describe(got))
+	}
+
+	if got := auditLog(t, s, &question.AuditRequest{Since: since, QuestionId: 6}); describe(got) != "[CREATED 6]" {
+		t.Errorf("AuditLog(since the 4th entry of question 6) = %s, want the entry of question 6", describe(got))
+	}
+
+	if got := auditLog(t, s, &question.AuditRequest{Since: since, QuestionId: 2}); len(got) != 0 {
+		t.Errorf("AuditLog(since the 4th entry of question 2) = %s, want none", describe(got))
+	}
+
+	if got := auditLog(t, s, &question.AuditRequest{Since: timestamppb.New(time.Now().Add(time.Hour))}); len(got) != 0 {
+		t.Errorf("AuditLog(since the future) = %s, want none", describe(got))
+	}
+
+	if got := auditLog(t, s, &question.AuditRequest{Since: timestamppb.New(time.Time{})}); len(got) != 7 {
+		t.Errorf("AuditLog(since year 1) returned %d entries, want 7", len(got))
+	}
+}
@@ -0,0 +1,59 @@
+package question
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/boltdb/bolt"
+)
+
+// Backuper is implemented by stores which can write a consistent snapshot
+// of their database while serving requests
+type Backuper interface {
+	Backup(w io.Writer) (int64, error)
+}
+
+var _ Backuper = (*Storage)(nil)
+
+// Backup writes a snapshot of the bolt database to w and returns its size.
+// It runs in a read transaction, so writers aren't blocked.
+// Unless w is a file, the snapshot is written to a temporary file next to
+// the database first, so a slow reader doesn't hold the transaction open
+// and the database doesn't grow while it is copied
+func (qs *Storage) Backup(w io.Writer) (int64, error) {
+	if f, ok := w.(*os.File); ok {
+		return qs.writeSnapshot(f)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(qs.db.Path()), ".backup-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := qs.writeSnapshot(tmp); err != nil {
+		return 0, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, tmp)
+}
+
+// writeSnapshot writes the database to w in a read transaction
+func (qs *Storage) writeSnapshot(w io.Writer) (int64, error) {
+	var n int64
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+
+	return n, err
+}
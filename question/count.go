@@ -0,0 +1,45 @@
+package question
+
+import "github.com/boltdb/bolt"
+
+// Counts holds numbers of stored questions
+type Counts struct {
+	Active   uint64
+	Inactive uint64
+}
+
+// Count returns numbers of active and inactive questions.
+// It reads sizes of the random pools, so it doesn't walk the buckets
+func (qs *Storage) Count() (*Counts, error) {
+	counts := &Counts{}
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		pools := tx.Bucket(poolsBucketName)
+		if pools == nil {
+			return nil
+		}
+
+		for _, isActive := range []bool{true, false} {
+			for _, isGood := range []bool{true, false} {
+				pool := pools.Bucket(poolName(isActive, isGood))
+				if pool == nil {
+					continue
+				}
+
+				if isActive {
+					counts.Active += poolSize(pool)
+				} else {
+					counts.Inactive += poolSize(pool)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,78 @@
+package question
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned when a requested question doesn't exist
+	ErrNotFound = errors.New("question not found")
+
+	// ErrInvalidArgument is returned when a request contains invalid values
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrConflict is returned when an expected version of a question
+	// doesn't match the stored one
+	ErrConflict = errors.New("version conflict")
+
+	// ErrRevisionUnavailable is returned when a watch can't be resumed from
+	// the requested revision since it is too old or hasn't happened yet
+	ErrRevisionUnavailable = errors.New("revision is unavailable")
+
+	// ErrUnsupported is returned when the storage can't do a requested operation
+	ErrUnsupported = errors.New("not supported by the storage")
+
+	// ErrDuplicate is returned when a written question is similar to stored ones
+	// and duplicates are rejected
+	ErrDuplicate = errors.New("question is a duplicate")
+)
+
+// RowError is returned by PutMany for a question which is skipped
+type RowError struct {
+	// Index is an index of the question in the written slice
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("question #%d: %v", e.Index+1, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// invalidArgument returns ErrInvalidArgument with a description
+func invalidArgument(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArgument, fmt.Sprintf(format, args...))
+}
+
+// rejected reports whether a write failed because of the question itself
+// rather than the storage, so other questions can still be written
+func rejected(err error) bool {
+	return errors.Is(err, ErrInvalidArgument) || errors.Is(err, ErrConflict) || errors.Is(err, ErrDuplicate)
+}
+
+// toStatus converts a storage error into a grpc status error
+func toStatus(err error, msg string) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, ErrInvalidArgument):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", msg, err)
+	case errors.Is(err, ErrConflict):
+		return status.Errorf(codes.Aborted, "%s: %v", msg, err)
+	case errors.Is(err, ErrRevisionUnavailable):
+		return status.Errorf(codes.OutOfRange, "%s: %v", msg, err)
+	case errors.Is(err, ErrUnsupported):
+		return status.Errorf(codes.Unimplemented, "%s: %v", msg, err)
+	case errors.Is(err, ErrDuplicate):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", msg, err)
+	}
+}
@@ -0,0 +1,230 @@
+package question
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// historyBucketName is a bucket with a nested bucket per question
+// which maps versions to revisions of the question
+var historyBucketName = []byte("history")
+
+// Actor describes who changes questions and how
+type Actor struct {
+	// Name is a name of the authenticated caller or its address
+	Name string
+
+	// Method is a name of the RPC which makes changes
+	Method string
+}
+
+// ActorStore is implemented by stores which record who changes questions
+type ActorStore interface {
+	// As returns the store which records changes as made by the actor
+	As(actor Actor) Store
+}
+
+// Historian is implemented by stores which keep versions of questions
+type Historian interface {
+	// History returns versions of a question, the oldest first
+	History(id uint64) ([]*Revision, error)
+
+	// Revert writes a question as it was at an earlier version
+	Revert(req *RevertRequest) (*Change, error)
+
+	// KeepVersions returns the store which keeps only the latest n versions
+	// of a question when it is written, 0 keeps all of them
+	KeepVersions(n int) Store
+}
+
+var (
+	_ ActorStore = (*Storage)(nil)
+	_ Historian  = (*Storage)(nil)
+)
+
+// As returns the storage which records changes as made by the actor
+func (qs *Storage) As(actor Actor) Store {
+	s := *qs
+	s.actor = actor
+	return &s
+}
+
+// KeepVersions returns the storage which removes versions of a written question
+// older than the latest n, 0 keeps all of them
+func (qs *Storage) KeepVersions(n int) Store {
+	s := *qs
+	s.keepVersions = n
+	return &s
+}
+
+// historyAdd saves a written version of the question with the current time and the actor.
+// Versions older than the latest keep are removed unless keep is 0
+func historyAdd(tx *bolt.Tx, q *Question, actor Actor, keep int) error {
+	parent, err := tx.CreateBucketIfNotExists(historyBucketName)
+	if err != nil {
+		return err
+	}
+
+	b, err := parent.CreateBucketIfNotExists(utils.Uinttob(q.Id))
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(&Revision{
+		Question:  q,
+		Timestamp: timestamppb.Now(),
+		Author:    actor.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(utils.Uinttob(q.Version), data); err != nil {
+		return err
+	}
+
+	if keep <= 0 || q.Version <= uint64(keep) {
+		return nil
+	}
+
+	var old [][]byte
+	last := utils.Uinttob(q.Version - uint64(keep))
+
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil && bytes.Compare(k, last) <= 0; k, _ = c.Next() {
+		old = append(old, append([]byte(nil), k...))
+	}
+
+	for _, k := range old {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// History returns kept versions of a question, the oldest first.
+// The current version is the last one, it has no time and author
+// if it was written before the history was kept
+func (qs *Storage) History(id uint64) ([]*Revision, error) {
+	var revisions []*Revision
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		if parent := tx.Bucket(historyBucketName); parent != nil {
+			if b := parent.Bucket(utils.Uinttob(id)); b != nil {
+				err := b.ForEach(func(k, v []byte) error {
+					rev := &Revision{}
+					if err := proto.Unmarshal(v, rev); err != nil {
+						return err
+					}
+
+					revisions = append(revisions, rev)
+					return nil
+				})
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return nil
+		}
+
+		data := b.Get(utils.Uinttob(id))
+		if data == nil {
+			return nil
+		}
+
+		current := &Question{}
+		if err := proto.Unmarshal(data, current); err != nil {
+			return err
+		}
+
+		if len(revisions) == 0 || revisions[len(revisions)-1].Question.Version != current.Version {
+			revisions = append(revisions, &Revision{Question: current})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return revisions, nil
+}
+
+// Revert writes the question as it was at req.ToVersion as its next version.
+// A non-zero req.Version must match the stored one
+func (qs *Storage) Revert(req *RevertRequest) (*Change, error) {
+	if req.ToVersion == 0 {
+		return nil, invalidArgument("version to revert to must be set")
+	}
+
+	var change *Change
+
+	err := qs.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return ErrNotFound
+		}
+
+		key := utils.Uinttob(req.Id)
+		data := b.Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+
+		current := &Question{}
+		if err := proto.Unmarshal(data, current); err != nil {
+			return err
+		}
+
+		if err := checkVersion(req.Id, current.Version, req.Version); err != nil {
+			return err
+		}
+
+		var rev []byte
+		if parent := tx.Bucket(historyBucketName); parent != nil {
+			if h := parent.Bucket(key); h != nil {
+				rev = h.Get(utils.Uinttob(req.ToVersion))
+			}
+		}
+
+		target := *current
+		if rev != nil {
+			revision := &Revision{}
+			if err := proto.Unmarshal(rev, revision); err != nil {
+				return err
+			}
+			target = *revision.Question
+		} else if req.ToVersion != current.Version {
+			return fmt.Errorf("%w: version %d of question %d isn't kept", ErrNotFound, req.ToVersion, req.Id)
+		}
+
+		target.Version = 0
+
+		var err error
+		change, err = qs.putQuestion(tx, b, target)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
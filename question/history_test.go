@@ -0,0 +1,126 @@
+package question_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+)
+
+// versions returns versions and texts of the revisions
+func versions(revisions []*question.Revision) string {
+	var result []string
+	for _, rev := range revisions {
+		result = append(result, fmt.Sprintf("%d:%s", rev.Question.Version, rev.Question.Text))
+	}
+
+	return fmt.Sprint(result)
+}
+
+// writeVersions puts a question and updates its text, one version per text
+func writeVersions(t *testing.T, s question.Store, texts ...string) uint64 {
+	t.Helper()
+
+	var id uint64
+	for _, text := range texts {
+		change, err := s.Put(question.Question{Id: id, Text: text})
+		if err != nil {
+			t.Fatalf("Couldn't put %q: %v", text, err)
+		}
+		id = change.After.Id
+	}
+
+	return id
+}
+
+func TestHistoryRevert(t *testing.T) {
+	s := newBoltStorage(t)
+	alice := s.As(question.Actor{Name: "alice", Method: "Put"})
+	bob := s.As(question.Actor{Name: "bob", Method: "Put"}).(question.Historian)
+
+	id := writeVersions(t, alice, "one", "two", "three")
+
+	revisions, err := s.History(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := versions(revisions); got != "[1:one 2:two 3:three]" {
+		t.Errorf("History() = %s, want 3 versions", got)
+	}
+
+	for _, rev := range revisions {
+		if rev.Author != "alice" || rev.Timestamp == nil {
+			t.Errorf("revision %d was written by %q at %v, want alice with a time", rev.Question.Version, rev.Author, rev.Timestamp)
+		}
+	}
+
+	if _, err := bob.Revert(&question.RevertRequest{Id: id, ToVersion: 1, Version: 2}); !errors.Is(err, question.ErrConflict) {
+		t.Errorf("Revert() with a stale version error = %v, want ErrConflict", err)
+	}
+
+	change, err := bob.Revert(&question.RevertRequest{Id: id, ToVersion: 1, Version: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change.Before.Version != 3 || change.After.Version != 4 || change.After.Text != "one" {
+		t.Errorf("Revert() = %v -> %v, want version 4 with the text of version 1", change.Before, change.After)
+	}
+
+	revisions, err = s.History(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last := revisions[len(revisions)-1]; last.Question.Version != 4 || last.Author != "bob" {
+		t.Errorf("the last revision = %d by %q, want 4 by bob", last.Question.Version, last.Author)
+	}
+
+	if _, err := bob.Revert(&question.RevertRequest{Id: id, ToVersion: 9}); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Revert() to an unknown version error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := bob.Revert(&question.RevertRequest{Id: id}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Revert() without a version error = %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := bob.Revert(&question.RevertRequest{Id: id + 1, ToVersion: 1}); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Revert() of an unknown question error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.History(id + 1); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("History() of an unknown question error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHistoryKeepVersions(t *testing.T) {
+	s := newBoltStorage(t).KeepVersions(2)
+	historian := s.(question.Historian)
+
+	id := writeVersions(t, s, "one", "two", "three", "four")
+
+	revisions, err := historian.History(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := versions(revisions); got != "[3:three 4:four]" {
+		t.Errorf("History() = %s, want the latest 2 versions", got)
+	}
+
+	_, err = historian.Revert(&question.RevertRequest{Id: id, ToVersion: 2})
+	if !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Revert() to a removed version error = %v, want ErrNotFound", err)
+	}
+
+	change, err := historian.Revert(&question.RevertRequest{Id: id, ToVersion: 3})
+	if err != nil || change.After.Version != 5 || change.After.Text != "three" {
+		t.Errorf("Revert() to a kept version = %v, %v, want version 5 with the text of version 3", change, err)
+	}
+
+	if revisions, _ := historian.History(id); versions(revisions) != "[4:four 5:three]" {
+		t.Errorf("History() after Revert = %s, want versions 4 and 5", versions(revisions))
+	}
+}
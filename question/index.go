@@ -0,0 +1,270 @@
+package question
+
+import (
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	activeIndexName   = []byte("index_active")
+	inactiveIndexName = []byte("index_inactive")
+	goodIndexName     = []byte("index_good")
+	badIndexName      = []byte("index_bad")
+	categoryIndexName = []byte("index_category")
+	tagIndexName      = []byte("index_tag")
+)
+
+// index is a secondary index bucket which holds IDs of matching questions.
+// Keys are the same 8-byte big endian IDs as in the questions bucket,
+// values are empty
+type index struct {
+	name  []byte
+	match func(q *Question) bool
+}
+
+var indexes = []index{
+	{name: activeIndexName, match: func(q *Question) bool { return q.IsActive }},
+	{name: inactiveIndexName, match: func(q *Question) bool { return !q.IsActive }},
+	{name: goodIndexName, match: func(q *Question) bool { return q.IsGood }},
+	{name: badIndexName, match: func(q *Question) bool { return !q.IsGood }},
+}
+
+// valueIndex is a bucket with a nested bucket per value of a question field.
+// Every nested bucket holds IDs of questions having this value the same way as index does
+type valueIndex struct {
+	name   []byte
+	values func(q *Question) []string
+}
+
+var valueIndexes = []valueIndex{
+	{name: categoryIndexName, values: func(q *Question) []string { return []string{q.Category} }},
+	{name: tagIndexName, values: func(q *Question) []string { return q.Tags }},
+}
+
+// activityIndexName returns a name of the index bucket for the given activity flag
+func activityIndexName(isActive bool) []byte {
+	if isActive {
+		return activeIndexName
+	}
+
+	return inactiveIndexName
+}
+
+// goodnessIndexName returns a name of the index bucket for the given isGood flag
+func goodnessIndexName(isGood bool) []byte {
+	if isGood {
+		return goodIndexName
+	}
+
+	return badIndexName
+}
+
+// indexGroup is a set of buckets built together and a function which adds a question to them
+type indexGroup struct {
+	names [][]byte
+	add   func(tx *bolt.Tx, q *Question) error
+}
+
+// indexGroups returns every group of index buckets, random pools,
+// the full-text and the similarity indexes
+func indexGroups() []indexGroup {
+	groups := []indexGroup{
+		{names: [][]byte{poolsBucketName}, add: poolAdd},
+		{names: [][]byte{searchTermsName, searchDocsName, searchMetaName}, add: searchAdd},
+		{names: [][]byte{similarBandsName, similarSignaturesName}, add: similarAdd},
+	}
+
+	for _, idx := range indexes {
+		groups = append(groups, indexGroup{names: [][]byte{idx.name}, add: idx.add})
+	}
+	for _, idx := range valueIndexes {
+		groups = append(groups, indexGroup{names: [][]byte{idx.name}, add: idx.add})
+	}
+
+	return groups
+}
+
+// createIndexes creates missing index buckets and returns functions
+// which fill the created groups with existing questions.
+// A group missing only some of its buckets is recreated entirely,
+// so it is never filled on top of stale data
+func createIndexes(tx *bolt.Tx) ([]func(tx *bolt.Tx, q *Question) error, error) {
+	var backfill []func(tx *bolt.Tx, q *Question) error
+
+	for _, group := range indexGroups() {
+		missing := false
+		for _, name := range group.names {
+			if tx.Bucket(name) == nil {
+				missing = true
+			}
+		}
+
+		if !missing {
+			continue
+		}
+
+		for _, name := range group.names {
+			if tx.Bucket(name) != nil {
+				if err := tx.DeleteBucket(name); err != nil {
+					return nil, err
+				}
+			}
+
+			if _, err := tx.CreateBucket(name); err != nil {
+				return nil, err
+			}
+		}
+
+		backfill = append(backfill, group.add)
+	}
+
+	return backfill, nil
+}
+
+// add puts question ID into the index if the question matches it
+func (idx index) add(tx *bolt.Tx, q *Question) error {
+	if !idx.match(q) {
+		return nil
+	}
+
+	b, err := tx.CreateBucketIfNotExists(idx.name)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(utils.Uinttob(q.Id), []byte{})
+}
+
+// add puts question ID into the nested bucket of every value of the question
+func (idx valueIndex) add(tx *bolt.Tx, q *Question) error {
+	parent, err := tx.CreateBucketIfNotExists(idx.name)
+	if err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(q.Id)
+	for _, value := range idx.values(q) {
+		if value == "" {
+			continue
+		}
+
+		b, err := parent.CreateBucketIfNotExists([]byte(value))
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(key, []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexAdd puts question ID into every index it matches, its random pool,
+// the full-text and the similarity indexes
+func indexAdd(tx *bolt.Tx, q *Question) error {
+	for _, group := range indexGroups() {
+		if err := group.add(tx, q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexRemove removes question ID from every index it matches, its random pool,
+// the full-text and the similarity indexes
+func indexRemove(tx *bolt.Tx, q *Question) error {
+	if err := poolRemove(tx, q); err != nil {
+		return err
+	}
+
+	if err := searchRemove(tx, q); err != nil {
+		return err
+	}
+
+	if err := similarRemove(tx, q); err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(q.Id)
+	for _, idx := range indexes {
+		if !idx.match(q) {
+			continue
+		}
+
+		b := tx.Bucket(idx.name)
+		if b == nil {
+			continue
+		}
+
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	for _, idx := range valueIndexes {
+		parent := tx.Bucket(idx.name)
+		if parent == nil {
+			continue
+		}
+
+		for _, value := range idx.values(q) {
+			if value == "" {
+				continue
+			}
+
+			b := parent.Bucket([]byte(value))
+			if b == nil {
+				continue
+			}
+
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// valueBuckets returns nested buckets of the value index for the given values.
+// Values without a bucket are returned as nil
+func valueBuckets(tx *bolt.Tx, name []byte, values []string) []*bolt.Bucket {
+	buckets := make([]*bolt.Bucket, len(values))
+	parent := tx.Bucket(name)
+	if parent == nil {
+		return buckets
+	}
+
+	for i, value := range values {
+		if value != "" {
+			buckets[i] = parent.Bucket([]byte(value))
+		}
+	}
+
+	return buckets
+}
+
+// containsAny reports whether the key is in at least one of the buckets
+func containsAny(buckets []*bolt.Bucket, key []byte) bool {
+	for _, b := range buckets {
+		if b != nil && b.Get(key) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsAll reports whether the key is in every bucket
+func containsAll(buckets []*bolt.Bucket, key []byte) bool {
+	for _, b := range buckets {
+		if b == nil || b.Get(key) == nil {
+			return false
+		}
+	}
+
+	return true
+}
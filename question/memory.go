@@ -0,0 +1,530 @@
+package question
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MemoryStore keeps questions in memory. It is useful for tests
+// and demos, all questions are lost when the process exits
+type MemoryStore struct {
+	*memoryData
+	actor Actor
+}
+
+// memoryData is shared by a MemoryStore and its copies made by As
+type memoryData struct {
+	mu        sync.RWMutex
+	questions map[uint64]*Question
+	ids       []uint64
+	sequence  uint64
+	trash     map[uint64]*TrashedQuestion
+}
+
+var (
+	_ Store      = (*MemoryStore)(nil)
+	_ ActorStore = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore creates a new empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		memoryData: &memoryData{
+			questions: make(map[uint64]*Question),
+			trash:     make(map[uint64]*TrashedQuestion),
+		},
+	}
+}
+
+// As returns the store which records deletions as made by the actor
+func (ms *MemoryStore) As(actor Actor) Store {
+	s := *ms
+	s.actor = actor
+	return &s
+}
+
+// Init does nothing, the store is ready right after creation
+func (ms *MemoryStore) Init() error {
+	return nil
+}
+
+// Put creates or updates a question
+func (ms *MemoryStore) Put(q Question) (*Change, error) {
+	if err := validate(&q); err != nil {
+		return nil, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.put(q)
+}
+
+// PutMany creates or updates questions at once, rejected questions are skipped.
+// With dryRun none of them is kept, but the changes are returned
+func (ms *MemoryStore) PutMany(questions []Question, dryRun bool) ([]*Change, []*RowError, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	sequence := ms.sequence
+	trash := make(map[uint64]*TrashedQuestion, len(ms.trash))
+	for id, trashed := range ms.trash {
+		trash[id] = trashed
+	}
+
+	var written []*Change
+	changes, rowErrs, err := putEach(questions, func(q Question) (*Change, error) {
+		change, err := ms.put(q)
+		if err == nil {
+			written = append(written, change)
+		}
+		return change, err
+	})
+
+	if err != nil || dryRun {
+		for i := len(written) - 1; i >= 0; i-- {
+			ms.remove(written[i].After.Id)
+			if written[i].Before != nil {
+				ms.insert(written[i].Before)
+			}
+		}
+		ms.sequence = sequence
+		ms.trash = trash
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return changes, rowErrs, nil
+}
+
+// put stores a question following the same rules as putQuestion.
+// ms.mu must be held for writing
+func (ms *MemoryStore) put(q Question) (*Change, error) {
+	id := q.Id
+	if id == 0 {
+		id = ms.sequence + 1
+	}
+
+	before := ms.questions[id]
+
+	var stored uint64
+	if before != nil {
+		stored = before.Version
+	} else if trashed := ms.trash[id]; trashed != nil {
+		stored = trashed.Question.Version
+	}
+
+	if err := checkVersion(id, stored, q.Version); err != nil {
+		return nil, err
+	}
+
+	if id > ms.sequence {
+		ms.sequence = id
+	}
+	delete(ms.trash, id)
+
+	q.Id, q.Version = id, stored+1
+
+	after := proto.Clone(&q).(*Question)
+	ms.remove(id)
+	ms.insert(after)
+
+	change := &Change{After: proto.Clone(after).(*Question)}
+	if before != nil {
+		change.Before = proto.Clone(before).(*Question)
+	}
+
+	return change, nil
+}
+
+// Load writes questions as they are,
+// the sequence is moved forward to the highest ID
+func (ms *MemoryStore) Load(questions []Question) error {
+	for i := range questions {
+		if questions[i].Id == 0 {
+			return invalidArgument("loaded question must have an ID")
+		}
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range questions {
+		q := proto.Clone(&questions[i]).(*Question)
+		ms.remove(q.Id)
+		ms.insert(q)
+		delete(ms.trash, q.Id)
+
+		if q.Id > ms.sequence {
+			ms.sequence = q.Id
+		}
+	}
+
+	return nil
+}
+
+// Sequence returns the last assigned ID
+func (ms *MemoryStore) Sequence() (uint64, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.sequence, nil
+}
+
+// SetSequence moves the sequence forward
+func (ms *MemoryStore) SetSequence(seq uint64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if seq > ms.sequence {
+		ms.sequence = seq
+	}
+
+	return nil
+}
+
+// insert adds a question keeping ids sorted. ms.mu must be held for writing
+func (ms *MemoryStore) insert(q *Question) {
+	ms.questions[q.Id] = q
+
+	i := sort.Search(len(ms.ids), func(i int) bool { return ms.ids[i] >= q.Id })
+	ms.ids = append(ms.ids, 0)
+	copy(ms.ids[i+1:], ms.ids[i:])
+	ms.ids[i] = q.Id
+}
+
+// remove deletes a question and returns it or nil if there is no such question.
+// ms.mu must be held for writing
+func (ms *MemoryStore) remove(id uint64) *Question {
+	q, ok := ms.questions[id]
+	if !ok {
+		return nil
+	}
+
+	delete(ms.questions, id)
+
+	i := sort.Search(len(ms.ids), func(i int) bool { return ms.ids[i] >= id })
+	ms.ids = append(ms.ids[:i], ms.ids[i+1:]...)
+
+	return q
+}
+
+// Update changes only the fields of a stored question listed in paths
+func (ms *MemoryStore) Update(q *Question, paths []string) (*Change, error) {
+	if err := validatePaths(paths); err != nil {
+		return nil, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	stored, ok := ms.questions[q.Id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	merged := proto.Clone(stored).(*Question)
+	if err := applyPaths(merged, q, paths); err != nil {
+		return nil, err
+	}
+
+	return ms.put(*merged)
+}
+
+// Get returns a question by it's ID
+func (ms *MemoryStore) Get(id uint64) (*Question, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	q, ok := ms.questions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return proto.Clone(q).(*Question), nil
+}
+
+// Delete moves a question into the trash
+func (ms *MemoryStore) Delete(id uint64) (*Change, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	old := ms.remove(id)
+	if old == nil {
+		return nil, ErrNotFound
+	}
+
+	ms.trash[id] = &TrashedQuestion{
+		Question:  proto.Clone(old).(*Question),
+		DeletedAt: timestamppb.Now(),
+		DeletedBy: ms.actor.Name,
+	}
+
+	return &Change{Before: old}, nil
+}
+
+// Trash returns deleted questions in order of IDs
+func (ms *MemoryStore) Trash() ([]*TrashedQuestion, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	result := make([]*TrashedQuestion, 0, len(ms.trash))
+	for _, trashed := range ms.trash {
+		result = append(result, proto.Clone(trashed).(*TrashedQuestion))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Question.Id < result[j].Question.Id })
+	return result, nil
+}
+
+// Restore moves a question from the trash back as its next version
+func (ms *MemoryStore) Restore(id uint64) (*Change, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	trashed := ms.trash[id]
+	if trashed == nil {
+		return nil, ErrNotFound
+	}
+
+	q := *trashed.Question
+	q.Version = 0
+
+	return ms.put(q)
+}
+
+// Purge removes a question with req.Id or, without an ID, all questions
+// deleted before req.DeletedBefore from the trash for good
+func (ms *MemoryStore) Purge(req *PurgeRequest) (uint64, error) {
+	before, err := purgeBefore(req)
+	if err != nil {
+		return 0, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if req.Id != 0 {
+		if ms.trash[req.Id] == nil {
+			return 0, ErrNotFound
+		}
+
+		delete(ms.trash, req.Id)
+		return 1, nil
+	}
+
+	var purged uint64
+	for id, trashed := range ms.trash {
+		if trashed.DeletedAt.AsTime().Before(before) {
+			delete(ms.trash, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// ForEach calls fn for every question in order of IDs.
+// Questions are copied first, so fn may modify the store
+func (ms *MemoryStore) ForEach(fn func(q *Question) error) error {
+	ms.mu.RLock()
+	questions := make([]*Question, 0, len(ms.ids))
+	for _, id := range ms.ids {
+		questions = append(questions, proto.Clone(ms.questions[id]).(*Question))
+	}
+	ms.mu.RUnlock()
+
+	for _, q := range questions {
+		if err := fn(q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Snapshot calls fn for every question in order of IDs holding the lock,
+// so fn must not modify the store
+func (ms *MemoryStore) Snapshot(fn func(q *Question) error) (uint64, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, id := range ms.ids {
+		if err := fn(proto.Clone(ms.questions[id]).(*Question)); err != nil {
+			return 0, err
+		}
+	}
+
+	return ms.sequence, nil
+}
+
+// Filter searches questions by filter the same way as Storage.Filter
+func (ms *MemoryStore) Filter(filter *Filter) (*QuestionList, error) {
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, invalidArgument("limit and offset must not be negative")
+	}
+
+	list := &QuestionList{
+		Questions: make([]*Question, 0, filter.Limit),
+	}
+
+	var after uint64
+	if filter.PageToken != "" {
+		key, err := decodePageToken(filter.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		after = binary.BigEndian.Uint64(key)
+	}
+
+	ignoreIds := make(map[uint64]bool, len(filter.IgnoreIds))
+	for _, id := range filter.IgnoreIds {
+		ignoreIds[id] = true
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	i := sort.Search(len(ms.ids), func(i int) bool { return ms.ids[i] > after })
+	next := func() *Question {
+		for ; i < len(ms.ids); i++ {
+			if q := ms.questions[ms.ids[i]]; q.IsActive == filter.IsActive {
+				i++
+				return q
+			}
+		}
+		return nil
+	}
+
+	var offset int32
+	var last *Question
+
+	q := next()
+	for ; q != nil && int32(len(list.Questions)) < filter.Limit; q = next() {
+		if ignoreIds[q.Id] {
+			continue
+		}
+
+		if filter.IsGood != nil && q.IsGood != filter.IsGood.Value {
+			continue
+		}
+
+		if len(filter.Categories) > 0 && !hasAny(filter.Categories, []string{q.Category}) {
+			continue
+		}
+
+		if len(filter.Tags) > 0 {
+			if filter.AllTags && !hasAll(filter.Tags, q.Tags) || !filter.AllTags && !hasAny(filter.Tags, q.Tags) {
+				continue
+			}
+		}
+
+		if offset < filter.Offset {
+			offset++
+			continue
+		}
+
+		list.Questions = append(list.Questions, proto.Clone(q).(*Question))
+		last = q
+	}
+
+	if q != nil && last != nil {
+		list.NextPageToken = encodePageToken(utils.Uinttob(last.Id))
+	}
+
+	return list, nil
+}
+
+// hasAny reports whether values contain any of the non-empty wanted values
+func hasAny(wanted, values []string) bool {
+	for _, w := range wanted {
+		if w != "" && hasValue(values, w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasAll reports whether values contain all of the wanted values.
+// An empty wanted value is never contained
+func hasAll(wanted, values []string) bool {
+	for _, w := range wanted {
+		if w == "" || !hasValue(values, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Random returns up to req.Count uniformly random questions
+// with the requested flags, skipping ignored IDs
+func (ms *MemoryStore) Random(req *RandomRequest) (*QuestionList, error) {
+	if req.Count < 0 {
+		return nil, invalidArgument("count must not be negative")
+	}
+
+	list := &QuestionList{}
+	if req.Count == 0 {
+		return list, nil
+	}
+
+	ignoreIds := make(map[uint64]bool, len(req.IgnoreIds))
+	for _, id := range req.IgnoreIds {
+		ignoreIds[id] = true
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var pool []*Question
+	for _, id := range ms.ids {
+		q := ms.questions[id]
+		if q.IsActive == req.IsActive && q.IsGood == req.IsGood && !ignoreIds[id] {
+			pool = append(pool, q)
+		}
+	}
+
+	for i := 0; i < len(pool) && int32(len(list.Questions)) < req.Count; i++ {
+		j := i + random.Intn(len(pool)-i)
+		pool[i], pool[j] = pool[j], pool[i]
+		list.Questions = append(list.Questions, proto.Clone(pool[i]).(*Question))
+	}
+
+	return list, nil
+}
+
+// Count returns numbers of active and inactive questions
+func (ms *MemoryStore) Count() (*Counts, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	counts := &Counts{}
+	for _, q := range ms.questions {
+		if q.IsActive {
+			counts.Active++
+		} else {
+			counts.Inactive++
+		}
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,19 @@
+package question_test
+
+import (
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/almostmoore/gbquestion/question/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) question.Store {
+		s := question.NewMemoryStore()
+		if err := s.Init(); err != nil {
+			t.Fatalf("Couldn't initialize the storage: %v", err)
+		}
+
+		return s
+	})
+}
@@ -11,14 +11,40 @@ It has these top-level messages:
 	Question
 	QuestionList
 	Filter
+	RandomRequest
+	WatchRequest
+	ChangeEvent
+	BulkPutRequest
+	BulkPutError
+	BulkPutResult
+	UpdateRequest
 	IdRequest
 	Void
+	BackupChunk
+	SearchRequest
+	ReindexResult
+	DuplicatesRequest
+	DuplicateCluster
+	DuplicateClusters
+	Revision
+	RevisionList
+	RevertRequest
+	TrashedQuestion
+	TrashList
+	PurgeRequest
+	PurgeResult
+	AuditEntry
+	AuditRequest
+	AuditEntryList
 */
 package question
 
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "google.golang.org/protobuf/types/known/fieldmaskpb"
+import google_protobuf1 "google.golang.org/protobuf/types/known/timestamppb"
+import google_protobuf2 "google.golang.org/protobuf/types/known/wrapperspb"
 
 import (
 	context "golang.org/x/net/context"
@@ -36,11 +62,65 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type ChangeEvent_Type int32
+
+const (
+	ChangeEvent_CREATED ChangeEvent_Type = 0
+	ChangeEvent_UPDATED ChangeEvent_Type = 1
+	ChangeEvent_DELETED ChangeEvent_Type = 2
+)
+
+var ChangeEvent_Type_name = map[int32]string{
+	0: "CREATED",
+	1: "UPDATED",
+	2: "DELETED",
+}
+var ChangeEvent_Type_value = map[string]int32{
+	"CREATED": 0,
+	"UPDATED": 1,
+	"DELETED": 2,
+}
+
+func (x ChangeEvent_Type) String() string {
+	return proto.EnumName(ChangeEvent_Type_name, int32(x))
+}
+func (ChangeEvent_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
+
+type AuditEntry_Kind int32
+
+const (
+	AuditEntry_CREATED AuditEntry_Kind = 0
+	AuditEntry_UPDATED AuditEntry_Kind = 1
+	AuditEntry_DELETED AuditEntry_Kind = 2
+	AuditEntry_PURGED  AuditEntry_Kind = 3
+)
+
+var AuditEntry_Kind_name = map[int32]string{
+	0: "CREATED",
+	1: "UPDATED",
+	2: "DELETED",
+	3: "PURGED",
+}
+var AuditEntry_Kind_value = map[string]int32{
+	"CREATED": 0,
+	"UPDATED": 1,
+	"DELETED": 2,
+	"PURGED":  3,
+}
+
+func (x AuditEntry_Kind) String() string {
+	return proto.EnumName(AuditEntry_Kind_name, int32(x))
+}
+func (AuditEntry_Kind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{25, 0} }
+
 type Question struct {
-	Id       uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	Text     string `protobuf:"bytes,2,opt,name=text" json:"text,omitempty"`
-	IsGood   bool   `protobuf:"varint,3,opt,name=isGood" json:"isGood,omitempty"`
-	IsActive bool   `protobuf:"varint,4,opt,name=isActive" json:"isActive,omitempty"`
+	Id       uint64   `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Text     string   `protobuf:"bytes,2,opt,name=text" json:"text,omitempty"`
+	IsGood   bool     `protobuf:"varint,3,opt,name=isGood" json:"isGood,omitempty"`
+	IsActive bool     `protobuf:"varint,4,opt,name=isActive" json:"isActive,omitempty"`
+	Category string   `protobuf:"bytes,5,opt,name=category" json:"category,omitempty"`
+	Tags     []string `protobuf:"bytes,6,rep,name=tags" json:"tags,omitempty"`
+	Version  uint64   `protobuf:"varint,7,opt,name=version" json:"version,omitempty"`
 }
 
 func (m *Question) Reset()                    { *m = Question{} }
@@ -76,8 +156,30 @@ func (m *Question) GetIsActive() bool {
 	return false
 }
 
+func (m *Question) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *Question) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Question) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 type QuestionList struct {
-	Questions []*Question `protobuf:"bytes,1,rep,name=questions" json:"questions,omitempty"`
+	Questions     []*Question `protobuf:"bytes,1,rep,name=questions" json:"questions,omitempty"`
+	NextPageToken string      `protobuf:"bytes,2,opt,name=nextPageToken" json:"nextPageToken,omitempty"`
 }
 
 func (m *QuestionList) Reset()                    { *m = QuestionList{} }
@@ -92,11 +194,24 @@ func (m *QuestionList) GetQuestions() []*Question {
 	return nil
 }
 
+func (m *QuestionList) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// Filter narrows questions down, isGood matches both good and bad ones unless it is set
 type Filter struct {
-	IsActive  bool     `protobuf:"varint,1,opt,name=isActive" json:"isActive,omitempty"`
-	Limit     int32    `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
-	Offset    int32    `protobuf:"varint,3,opt,name=offset" json:"offset,omitempty"`
-	IgnoreIds []uint64 `protobuf:"varint,4,rep,packed,name=ignoreIds" json:"ignoreIds,omitempty"`
+	IsActive   bool                        `protobuf:"varint,1,opt,name=isActive" json:"isActive,omitempty"`
+	Limit      int32                       `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+	Offset     int32                       `protobuf:"varint,3,opt,name=offset" json:"offset,omitempty"`
+	IgnoreIds  []uint64                    `protobuf:"varint,4,rep,packed,name=ignoreIds" json:"ignoreIds,omitempty"`
+	PageToken  string                      `protobuf:"bytes,5,opt,name=pageToken" json:"pageToken,omitempty"`
+	Categories []string                    `protobuf:"bytes,6,rep,name=categories" json:"categories,omitempty"`
+	Tags       []string                    `protobuf:"bytes,7,rep,name=tags" json:"tags,omitempty"`
+	AllTags    bool                        `protobuf:"varint,8,opt,name=allTags" json:"allTags,omitempty"`
+	IsGood     *google_protobuf2.BoolValue `protobuf:"bytes,9,opt,name=isGood" json:"isGood,omitempty"`
 }
 
 func (m *Filter) Reset()                    { *m = Filter{} }
@@ -132,189 +247,1461 @@ func (m *Filter) GetIgnoreIds() []uint64 {
 	return nil
 }
 
-type IdRequest struct {
-	Id uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+func (m *Filter) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
 }
 
-func (m *IdRequest) Reset()                    { *m = IdRequest{} }
-func (m *IdRequest) String() string            { return proto.CompactTextString(m) }
-func (*IdRequest) ProtoMessage()               {}
-func (*IdRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (m *Filter) GetCategories() []string {
+	if m != nil {
+		return m.Categories
+	}
+	return nil
+}
 
-func (m *IdRequest) GetId() uint64 {
+func (m *Filter) GetTags() []string {
 	if m != nil {
-		return m.Id
+		return m.Tags
 	}
-	return 0
+	return nil
 }
 
-type Void struct {
+func (m *Filter) GetAllTags() bool {
+	if m != nil {
+		return m.AllTags
+	}
+	return false
 }
 
-func (m *Void) Reset()                    { *m = Void{} }
-func (m *Void) String() string            { return proto.CompactTextString(m) }
-func (*Void) ProtoMessage()               {}
-func (*Void) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (m *Filter) GetIsGood() *google_protobuf2.BoolValue {
+	if m != nil {
+		return m.IsGood
+	}
+	return nil
+}
 
-func init() {
-	proto.RegisterType((*Question)(nil), "question.Question")
-	proto.RegisterType((*QuestionList)(nil), "question.QuestionList")
-	proto.RegisterType((*Filter)(nil), "question.Filter")
-	proto.RegisterType((*IdRequest)(nil), "question.IdRequest")
-	proto.RegisterType((*Void)(nil), "question.Void")
+type RandomRequest struct {
+	Count     int32    `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	IsActive  bool     `protobuf:"varint,2,opt,name=isActive" json:"isActive,omitempty"`
+	IsGood    bool     `protobuf:"varint,3,opt,name=isGood" json:"isGood,omitempty"`
+	IgnoreIds []uint64 `protobuf:"varint,4,rep,packed,name=ignoreIds" json:"ignoreIds,omitempty"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *RandomRequest) Reset()                    { *m = RandomRequest{} }
+func (m *RandomRequest) String() string            { return proto.CompactTextString(m) }
+func (*RandomRequest) ProtoMessage()               {}
+func (*RandomRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *RandomRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
 
-// Client API for Questions service
+func (m *RandomRequest) GetIsActive() bool {
+	if m != nil {
+		return m.IsActive
+	}
+	return false
+}
 
-type QuestionsClient interface {
-	List(ctx context.Context, in *Filter, opts ...grpc.CallOption) (*QuestionList, error)
-	Put(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Question, error)
-	Get(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error)
-	Delete(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Void, error)
+func (m *RandomRequest) GetIsGood() bool {
+	if m != nil {
+		return m.IsGood
+	}
+	return false
 }
 
-type questionsClient struct {
-	cc *grpc.ClientConn
+func (m *RandomRequest) GetIgnoreIds() []uint64 {
+	if m != nil {
+		return m.IgnoreIds
+	}
+	return nil
 }
 
-func NewQuestionsClient(cc *grpc.ClientConn) QuestionsClient {
-	return &questionsClient{cc}
+type WatchRequest struct {
+	FromRevision uint64 `protobuf:"varint,1,opt,name=fromRevision" json:"fromRevision,omitempty"`
 }
 
-func (c *questionsClient) List(ctx context.Context, in *Filter, opts ...grpc.CallOption) (*QuestionList, error) {
-	out := new(QuestionList)
-	err := grpc.Invoke(ctx, "/question.Questions/List", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *WatchRequest) Reset()                    { *m = WatchRequest{} }
+func (m *WatchRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()               {}
+func (*WatchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *WatchRequest) GetFromRevision() uint64 {
+	if m != nil {
+		return m.FromRevision
 	}
-	return out, nil
+	return 0
 }
 
-func (c *questionsClient) Put(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Question, error) {
-	out := new(Question)
-	err := grpc.Invoke(ctx, "/question.Questions/Put", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type ChangeEvent struct {
+	Revision uint64           `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	Type     ChangeEvent_Type `protobuf:"varint,2,opt,name=type,enum=question.ChangeEvent_Type" json:"type,omitempty"`
+	Question *Question        `protobuf:"bytes,3,opt,name=question" json:"question,omitempty"`
 }
 
-func (c *questionsClient) Get(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error) {
-	out := new(Question)
-	err := grpc.Invoke(ctx, "/question.Questions/Get", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ChangeEvent) Reset()                    { *m = ChangeEvent{} }
+func (m *ChangeEvent) String() string            { return proto.CompactTextString(m) }
+func (*ChangeEvent) ProtoMessage()               {}
+func (*ChangeEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ChangeEvent) GetRevision() uint64 {
+	if m != nil {
+		return m.Revision
 	}
-	return out, nil
+	return 0
 }
 
-func (c *questionsClient) Delete(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Void, error) {
-	out := new(Void)
-	err := grpc.Invoke(ctx, "/question.Questions/Delete", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ChangeEvent) GetType() ChangeEvent_Type {
+	if m != nil {
+		return m.Type
 	}
-	return out, nil
+	return ChangeEvent_CREATED
 }
 
-// Server API for Questions service
-
-type QuestionsServer interface {
-	List(context.Context, *Filter) (*QuestionList, error)
-	Put(context.Context, *Question) (*Question, error)
-	Get(context.Context, *IdRequest) (*Question, error)
-	Delete(context.Context, *IdRequest) (*Void, error)
+func (m *ChangeEvent) GetQuestion() *Question {
+	if m != nil {
+		return m.Question
+	}
+	return nil
 }
 
-func RegisterQuestionsServer(s *grpc.Server, srv QuestionsServer) {
-	s.RegisterService(&_Questions_serviceDesc, srv)
+type BulkPutRequest struct {
+	Question    *Question `protobuf:"bytes,1,opt,name=question" json:"question,omitempty"`
+	Row         uint64    `protobuf:"varint,2,opt,name=row" json:"row,omitempty"`
+	DryRun      bool      `protobuf:"varint,3,opt,name=dryRun" json:"dryRun,omitempty"`
+	PreserveIds bool      `protobuf:"varint,4,opt,name=preserveIds" json:"preserveIds,omitempty"`
 }
 
-func _Questions_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Filter)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(QuestionsServer).List(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/question.Questions/List",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(QuestionsServer).List(ctx, req.(*Filter))
+func (m *BulkPutRequest) Reset()                    { *m = BulkPutRequest{} }
+func (m *BulkPutRequest) String() string            { return proto.CompactTextString(m) }
+func (*BulkPutRequest) ProtoMessage()               {}
+func (*BulkPutRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *BulkPutRequest) GetQuestion() *Question {
+	if m != nil {
+		return m.Question
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _Questions_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Question)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(QuestionsServer).Put(ctx, in)
+func (m *BulkPutRequest) GetRow() uint64 {
+	if m != nil {
+		return m.Row
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/question.Questions/Put",
+	return 0
+}
+
+func (m *BulkPutRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(QuestionsServer).Put(ctx, req.(*Question))
+	return false
+}
+
+func (m *BulkPutRequest) GetPreserveIds() bool {
+	if m != nil {
+		return m.PreserveIds
 	}
-	return interceptor(ctx, in, info, handler)
+	return false
 }
 
-func _Questions_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(IdRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+type BulkPutError struct {
+	Row     uint64 `protobuf:"varint,1,opt,name=row" json:"row,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *BulkPutError) Reset()                    { *m = BulkPutError{} }
+func (m *BulkPutError) String() string            { return proto.CompactTextString(m) }
+func (*BulkPutError) ProtoMessage()               {}
+func (*BulkPutError) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *BulkPutError) GetRow() uint64 {
+	if m != nil {
+		return m.Row
 	}
-	if interceptor == nil {
-		return srv.(QuestionsServer).Get(ctx, in)
+	return 0
+}
+
+func (m *BulkPutError) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/question.Questions/Get",
+	return ""
+}
+
+type BulkPutResult struct {
+	Created  uint64          `protobuf:"varint,1,opt,name=created" json:"created,omitempty"`
+	Updated  uint64          `protobuf:"varint,2,opt,name=updated" json:"updated,omitempty"`
+	Errors   []*BulkPutError `protobuf:"bytes,3,rep,name=errors" json:"errors,omitempty"`
+	Warnings []*BulkPutError `protobuf:"bytes,4,rep,name=warnings" json:"warnings,omitempty"`
+}
+
+func (m *BulkPutResult) Reset()                    { *m = BulkPutResult{} }
+func (m *BulkPutResult) String() string            { return proto.CompactTextString(m) }
+func (*BulkPutResult) ProtoMessage()               {}
+func (*BulkPutResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *BulkPutResult) GetCreated() uint64 {
+	if m != nil {
+		return m.Created
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(QuestionsServer).Get(ctx, req.(*IdRequest))
+	return 0
+}
+
+func (m *BulkPutResult) GetUpdated() uint64 {
+	if m != nil {
+		return m.Updated
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _Questions_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(IdRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *BulkPutResult) GetErrors() []*BulkPutError {
+	if m != nil {
+		return m.Errors
 	}
-	if interceptor == nil {
-		return srv.(QuestionsServer).Delete(ctx, in)
+	return nil
+}
+
+func (m *BulkPutResult) GetWarnings() []*BulkPutError {
+	if m != nil {
+		return m.Warnings
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/question.Questions/Delete",
+	return nil
+}
+
+type UpdateRequest struct {
+	Question   *Question                  `protobuf:"bytes,1,opt,name=question" json:"question,omitempty"`
+	UpdateMask *google_protobuf.FieldMask `protobuf:"bytes,2,opt,name=updateMask" json:"updateMask,omitempty"`
+}
+
+func (m *UpdateRequest) Reset()                    { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()               {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *UpdateRequest) GetQuestion() *Question {
+	if m != nil {
+		return m.Question
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(QuestionsServer).Delete(ctx, req.(*IdRequest))
+	return nil
+}
+
+func (m *UpdateRequest) GetUpdateMask() *google_protobuf.FieldMask {
+	if m != nil {
+		return m.UpdateMask
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _Questions_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "question.Questions",
-	HandlerType: (*QuestionsServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
+type IdRequest struct {
+	Id uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *IdRequest) Reset()                    { *m = IdRequest{} }
+func (m *IdRequest) String() string            { return proto.CompactTextString(m) }
+func (*IdRequest) ProtoMessage()               {}
+func (*IdRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *IdRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type Void struct {
+}
+
+func (m *Void) Reset()                    { *m = Void{} }
+func (m *Void) String() string            { return proto.CompactTextString(m) }
+func (*Void) ProtoMessage()               {}
+func (*Void) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+// BackupChunk is a part of a bolt database snapshot.
+// The last chunk has no data, but the size and SHA-256 of the whole snapshot
+type BackupChunk struct {
+	Data   []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Size   int64  `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+	Sha256 string `protobuf:"bytes,3,opt,name=sha256" json:"sha256,omitempty"`
+}
+
+func (m *BackupChunk) Reset()                    { *m = BackupChunk{} }
+func (m *BackupChunk) String() string            { return proto.CompactTextString(m) }
+func (*BackupChunk) ProtoMessage()               {}
+func (*BackupChunk) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *BackupChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *BackupChunk) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *BackupChunk) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+// SearchRequest is a full-text query. Every word must match,
+// a word ending with * matches as a prefix.
+// limit is 20 by default and at most 100
+type SearchRequest struct {
+	Query      string `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+	Limit      int32  `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+	Offset     int32  `protobuf:"varint,3,opt,name=offset" json:"offset,omitempty"`
+	OnlyActive bool   `protobuf:"varint,4,opt,name=onlyActive" json:"onlyActive,omitempty"`
+}
+
+func (m *SearchRequest) Reset()                    { *m = SearchRequest{} }
+func (m *SearchRequest) String() string            { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()               {}
+func (*SearchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetOnlyActive() bool {
+	if m != nil {
+		return m.OnlyActive
+	}
+	return false
+}
+
+type ReindexResult struct {
+	Questions uint64 `protobuf:"varint,1,opt,name=questions" json:"questions,omitempty"`
+}
+
+func (m *ReindexResult) Reset()                    { *m = ReindexResult{} }
+func (m *ReindexResult) String() string            { return proto.CompactTextString(m) }
+func (*ReindexResult) ProtoMessage()               {}
+func (*ReindexResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ReindexResult) GetQuestions() uint64 {
+	if m != nil {
+		return m.Questions
+	}
+	return 0
+}
+
+// DuplicatesRequest asks for clusters of questions with at least
+// the given similarity of texts from 0 to 1, 0 means the default one
+type DuplicatesRequest struct {
+	Threshold float64 `protobuf:"fixed64,1,opt,name=threshold" json:"threshold,omitempty"`
+}
+
+func (m *DuplicatesRequest) Reset()                    { *m = DuplicatesRequest{} }
+func (m *DuplicatesRequest) String() string            { return proto.CompactTextString(m) }
+func (*DuplicatesRequest) ProtoMessage()               {}
+func (*DuplicatesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *DuplicatesRequest) GetThreshold() float64 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+type DuplicateCluster struct {
+	Ids []uint64 `protobuf:"varint,1,rep,packed,name=ids" json:"ids,omitempty"`
+}
+
+func (m *DuplicateCluster) Reset()                    { *m = DuplicateCluster{} }
+func (m *DuplicateCluster) String() string            { return proto.CompactTextString(m) }
+func (*DuplicateCluster) ProtoMessage()               {}
+func (*DuplicateCluster) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *DuplicateCluster) GetIds() []uint64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type DuplicateClusters struct {
+	Clusters []*DuplicateCluster `protobuf:"bytes,1,rep,name=clusters" json:"clusters,omitempty"`
+}
+
+func (m *DuplicateClusters) Reset()                    { *m = DuplicateClusters{} }
+func (m *DuplicateClusters) String() string            { return proto.CompactTextString(m) }
+func (*DuplicateClusters) ProtoMessage()               {}
+func (*DuplicateClusters) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *DuplicateClusters) GetClusters() []*DuplicateCluster {
+	if m != nil {
+		return m.Clusters
+	}
+	return nil
+}
+
+// Revision is a version of a question with the time it was written and its author
+type Revision struct {
+	Question  *Question                   `protobuf:"bytes,1,opt,name=question" json:"question,omitempty"`
+	Timestamp *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	Author    string                      `protobuf:"bytes,3,opt,name=author" json:"author,omitempty"`
+}
+
+func (m *Revision) Reset()                    { *m = Revision{} }
+func (m *Revision) String() string            { return proto.CompactTextString(m) }
+func (*Revision) ProtoMessage()               {}
+func (*Revision) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *Revision) GetQuestion() *Question {
+	if m != nil {
+		return m.Question
+	}
+	return nil
+}
+
+func (m *Revision) GetTimestamp() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *Revision) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+type RevisionList struct {
+	Revisions []*Revision `protobuf:"bytes,1,rep,name=revisions" json:"revisions,omitempty"`
+}
+
+func (m *RevisionList) Reset()                    { *m = RevisionList{} }
+func (m *RevisionList) String() string            { return proto.CompactTextString(m) }
+func (*RevisionList) ProtoMessage()               {}
+func (*RevisionList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *RevisionList) GetRevisions() []*Revision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+// RevertRequest writes the question as it was at toVersion as its next version.
+// A non-zero version must match the stored one
+type RevertRequest struct {
+	Id        uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	ToVersion uint64 `protobuf:"varint,2,opt,name=toVersion" json:"toVersion,omitempty"`
+	Version   uint64 `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *RevertRequest) Reset()                    { *m = RevertRequest{} }
+func (m *RevertRequest) String() string            { return proto.CompactTextString(m) }
+func (*RevertRequest) ProtoMessage()               {}
+func (*RevertRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *RevertRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *RevertRequest) GetToVersion() uint64 {
+	if m != nil {
+		return m.ToVersion
+	}
+	return 0
+}
+
+func (m *RevertRequest) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// TrashedQuestion is a deleted question kept in the trash until it is purged
+type TrashedQuestion struct {
+	Question  *Question                   `protobuf:"bytes,1,opt,name=question" json:"question,omitempty"`
+	DeletedAt *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=deletedAt" json:"deletedAt,omitempty"`
+	DeletedBy string                      `protobuf:"bytes,3,opt,name=deletedBy" json:"deletedBy,omitempty"`
+}
+
+func (m *TrashedQuestion) Reset()                    { *m = TrashedQuestion{} }
+func (m *TrashedQuestion) String() string            { return proto.CompactTextString(m) }
+func (*TrashedQuestion) ProtoMessage()               {}
+func (*TrashedQuestion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *TrashedQuestion) GetQuestion() *Question {
+	if m != nil {
+		return m.Question
+	}
+	return nil
+}
+
+func (m *TrashedQuestion) GetDeletedAt() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return nil
+}
+
+func (m *TrashedQuestion) GetDeletedBy() string {
+	if m != nil {
+		return m.DeletedBy
+	}
+	return ""
+}
+
+type TrashList struct {
+	Questions []*TrashedQuestion `protobuf:"bytes,1,rep,name=questions" json:"questions,omitempty"`
+}
+
+func (m *TrashList) Reset()                    { *m = TrashList{} }
+func (m *TrashList) String() string            { return proto.CompactTextString(m) }
+func (*TrashList) ProtoMessage()               {}
+func (*TrashList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *TrashList) GetQuestions() []*TrashedQuestion {
+	if m != nil {
+		return m.Questions
+	}
+	return nil
+}
+
+// PurgeRequest removes a question from the trash for good or,
+// without an ID, all questions deleted before deletedBefore
+type PurgeRequest struct {
+	Id            uint64                      `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	DeletedBefore *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=deletedBefore" json:"deletedBefore,omitempty"`
+}
+
+func (m *PurgeRequest) Reset()                    { *m = PurgeRequest{} }
+func (m *PurgeRequest) String() string            { return proto.CompactTextString(m) }
+func (*PurgeRequest) ProtoMessage()               {}
+func (*PurgeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *PurgeRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *PurgeRequest) GetDeletedBefore() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.DeletedBefore
+	}
+	return nil
+}
+
+type PurgeResult struct {
+	Questions uint64 `protobuf:"varint,1,opt,name=questions" json:"questions,omitempty"`
+}
+
+func (m *PurgeResult) Reset()                    { *m = PurgeResult{} }
+func (m *PurgeResult) String() string            { return proto.CompactTextString(m) }
+func (*PurgeResult) ProtoMessage()               {}
+func (*PurgeResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *PurgeResult) GetQuestions() uint64 {
+	if m != nil {
+		return m.Questions
+	}
+	return 0
+}
+
+// AuditEntry records a change of a question: the RPC which made it, the caller,
+// the time, the kind of the change and the question before and after it.
+// A deleted question stays in the trash, a purged one is removed for good
+type AuditEntry struct {
+	Timestamp  *google_protobuf1.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Method     string                      `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
+	Caller     string                      `protobuf:"bytes,3,opt,name=caller" json:"caller,omitempty"`
+	QuestionId uint64                      `protobuf:"varint,4,opt,name=questionId" json:"questionId,omitempty"`
+	Before     *Question                   `protobuf:"bytes,5,opt,name=before" json:"before,omitempty"`
+	After      *Question                   `protobuf:"bytes,6,opt,name=after" json:"after,omitempty"`
+	Kind       AuditEntry_Kind             `protobuf:"varint,7,opt,name=kind,enum=question.AuditEntry_Kind" json:"kind,omitempty"`
+}
+
+func (m *AuditEntry) Reset()                    { *m = AuditEntry{} }
+func (m *AuditEntry) String() string            { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()               {}
+func (*AuditEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *AuditEntry) GetTimestamp() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetCaller() string {
+	if m != nil {
+		return m.Caller
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetQuestionId() uint64 {
+	if m != nil {
+		return m.QuestionId
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetBefore() *Question {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetAfter() *Question {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetKind() AuditEntry_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return AuditEntry_CREATED
+}
+
+// AuditRequest pages through the audit log, the oldest entries first.
+// questionId and since narrow it down, limit is 100 by default
+type AuditRequest struct {
+	QuestionId uint64                      `protobuf:"varint,1,opt,name=questionId" json:"questionId,omitempty"`
+	Since      *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=since" json:"since,omitempty"`
+	Limit      int32                       `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+	PageToken  string                      `protobuf:"bytes,4,opt,name=pageToken" json:"pageToken,omitempty"`
+}
+
+func (m *AuditRequest) Reset()                    { *m = AuditRequest{} }
+func (m *AuditRequest) String() string            { return proto.CompactTextString(m) }
+func (*AuditRequest) ProtoMessage()               {}
+func (*AuditRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *AuditRequest) GetQuestionId() uint64 {
+	if m != nil {
+		return m.QuestionId
+	}
+	return 0
+}
+
+func (m *AuditRequest) GetSince() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *AuditRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *AuditRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type AuditEntryList struct {
+	Entries       []*AuditEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	NextPageToken string        `protobuf:"bytes,2,opt,name=nextPageToken" json:"nextPageToken,omitempty"`
+}
+
+func (m *AuditEntryList) Reset()                    { *m = AuditEntryList{} }
+func (m *AuditEntryList) String() string            { return proto.CompactTextString(m) }
+func (*AuditEntryList) ProtoMessage()               {}
+func (*AuditEntryList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *AuditEntryList) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *AuditEntryList) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Question)(nil), "question.Question")
+	proto.RegisterType((*QuestionList)(nil), "question.QuestionList")
+	proto.RegisterType((*Filter)(nil), "question.Filter")
+	proto.RegisterType((*RandomRequest)(nil), "question.RandomRequest")
+	proto.RegisterType((*WatchRequest)(nil), "question.WatchRequest")
+	proto.RegisterType((*ChangeEvent)(nil), "question.ChangeEvent")
+	proto.RegisterType((*BulkPutRequest)(nil), "question.BulkPutRequest")
+	proto.RegisterType((*BulkPutError)(nil), "question.BulkPutError")
+	proto.RegisterType((*BulkPutResult)(nil), "question.BulkPutResult")
+	proto.RegisterType((*UpdateRequest)(nil), "question.UpdateRequest")
+	proto.RegisterType((*IdRequest)(nil), "question.IdRequest")
+	proto.RegisterType((*Void)(nil), "question.Void")
+	proto.RegisterType((*BackupChunk)(nil), "question.BackupChunk")
+	proto.RegisterType((*SearchRequest)(nil), "question.SearchRequest")
+	proto.RegisterType((*ReindexResult)(nil), "question.ReindexResult")
+	proto.RegisterType((*DuplicatesRequest)(nil), "question.DuplicatesRequest")
+	proto.RegisterType((*DuplicateCluster)(nil), "question.DuplicateCluster")
+	proto.RegisterType((*DuplicateClusters)(nil), "question.DuplicateClusters")
+	proto.RegisterType((*Revision)(nil), "question.Revision")
+	proto.RegisterType((*RevisionList)(nil), "question.RevisionList")
+	proto.RegisterType((*RevertRequest)(nil), "question.RevertRequest")
+	proto.RegisterType((*TrashedQuestion)(nil), "question.TrashedQuestion")
+	proto.RegisterType((*TrashList)(nil), "question.TrashList")
+	proto.RegisterType((*PurgeRequest)(nil), "question.PurgeRequest")
+	proto.RegisterType((*PurgeResult)(nil), "question.PurgeResult")
+	proto.RegisterType((*AuditEntry)(nil), "question.AuditEntry")
+	proto.RegisterType((*AuditRequest)(nil), "question.AuditRequest")
+	proto.RegisterType((*AuditEntryList)(nil), "question.AuditEntryList")
+	proto.RegisterEnum("question.ChangeEvent_Type", ChangeEvent_Type_name, ChangeEvent_Type_value)
+	proto.RegisterEnum("question.AuditEntry_Kind", AuditEntry_Kind_name, AuditEntry_Kind_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Questions service
+
+type QuestionsClient interface {
+	List(ctx context.Context, in *Filter, opts ...grpc.CallOption) (*QuestionList, error)
+	Put(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Question, error)
+	Get(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error)
+	Delete(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Void, error)
+	Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*QuestionList, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Questions_WatchClient, error)
+	BulkPut(ctx context.Context, opts ...grpc.CallOption) (Questions_BulkPutClient, error)
+	Export(ctx context.Context, in *Void, opts ...grpc.CallOption) (Questions_ExportClient, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Question, error)
+	Backup(ctx context.Context, in *Void, opts ...grpc.CallOption) (Questions_BackupClient, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*QuestionList, error)
+	Reindex(ctx context.Context, in *Void, opts ...grpc.CallOption) (*ReindexResult, error)
+	Duplicates(ctx context.Context, in *DuplicatesRequest, opts ...grpc.CallOption) (*DuplicateClusters, error)
+	History(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*RevisionList, error)
+	Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*Question, error)
+	Trash(ctx context.Context, in *Void, opts ...grpc.CallOption) (*TrashList, error)
+	Restore(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error)
+	Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResult, error)
+	AuditLog(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditEntryList, error)
+}
+
+type questionsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewQuestionsClient(cc *grpc.ClientConn) QuestionsClient {
+	return &questionsClient{cc}
+}
+
+func (c *questionsClient) List(ctx context.Context, in *Filter, opts ...grpc.CallOption) (*QuestionList, error) {
+	out := new(QuestionList)
+	err := grpc.Invoke(ctx, "/question.Questions/List", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Put(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Question, error) {
+	out := new(Question)
+	err := grpc.Invoke(ctx, "/question.Questions/Put", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Get(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error) {
+	out := new(Question)
+	err := grpc.Invoke(ctx, "/question.Questions/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Delete(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := grpc.Invoke(ctx, "/question.Questions/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*QuestionList, error) {
+	out := new(QuestionList)
+	err := grpc.Invoke(ctx, "/question.Questions/Random", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Questions_WatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Questions_serviceDesc.Streams[0], c.cc, "/question.Questions/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &questionsWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Questions_WatchClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type questionsWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *questionsWatchClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *questionsClient) BulkPut(ctx context.Context, opts ...grpc.CallOption) (Questions_BulkPutClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Questions_serviceDesc.Streams[1], c.cc, "/question.Questions/BulkPut", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &questionsBulkPutClient{stream}
+	return x, nil
+}
+
+type Questions_BulkPutClient interface {
+	Send(*BulkPutRequest) error
+	CloseAndRecv() (*BulkPutResult, error)
+	grpc.ClientStream
+}
+
+type questionsBulkPutClient struct {
+	grpc.ClientStream
+}
+
+func (x *questionsBulkPutClient) Send(m *BulkPutRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *questionsBulkPutClient) CloseAndRecv() (*BulkPutResult, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkPutResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *questionsClient) Export(ctx context.Context, in *Void, opts ...grpc.CallOption) (Questions_ExportClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Questions_serviceDesc.Streams[2], c.cc, "/question.Questions/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &questionsExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Questions_ExportClient interface {
+	Recv() (*Question, error)
+	grpc.ClientStream
+}
+
+type questionsExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *questionsExportClient) Recv() (*Question, error) {
+	m := new(Question)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *questionsClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Question, error) {
+	out := new(Question)
+	err := grpc.Invoke(ctx, "/question.Questions/Update", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Backup(ctx context.Context, in *Void, opts ...grpc.CallOption) (Questions_BackupClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Questions_serviceDesc.Streams[3], c.cc, "/question.Questions/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &questionsBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Questions_BackupClient interface {
+	Recv() (*BackupChunk, error)
+	grpc.ClientStream
+}
+
+type questionsBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *questionsBackupClient) Recv() (*BackupChunk, error) {
+	m := new(BackupChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *questionsClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*QuestionList, error) {
+	out := new(QuestionList)
+	err := grpc.Invoke(ctx, "/question.Questions/Search", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Reindex(ctx context.Context, in *Void, opts ...grpc.CallOption) (*ReindexResult, error) {
+	out := new(ReindexResult)
+	err := grpc.Invoke(ctx, "/question.Questions/Reindex", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Duplicates(ctx context.Context, in *DuplicatesRequest, opts ...grpc.CallOption) (*DuplicateClusters, error) {
+	out := new(DuplicateClusters)
+	err := grpc.Invoke(ctx, "/question.Questions/Duplicates", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) History(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*RevisionList, error) {
+	out := new(RevisionList)
+	err := grpc.Invoke(ctx, "/question.Questions/History", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*Question, error) {
+	out := new(Question)
+	err := grpc.Invoke(ctx, "/question.Questions/Revert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Trash(ctx context.Context, in *Void, opts ...grpc.CallOption) (*TrashList, error) {
+	out := new(TrashList)
+	err := grpc.Invoke(ctx, "/question.Questions/Trash", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Restore(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Question, error) {
+	out := new(Question)
+	err := grpc.Invoke(ctx, "/question.Questions/Restore", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResult, error) {
+	out := new(PurgeResult)
+	err := grpc.Invoke(ctx, "/question.Questions/Purge", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *questionsClient) AuditLog(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditEntryList, error) {
+	out := new(AuditEntryList)
+	err := grpc.Invoke(ctx, "/question.Questions/AuditLog", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Questions service
+
+type QuestionsServer interface {
+	List(context.Context, *Filter) (*QuestionList, error)
+	Put(context.Context, *Question) (*Question, error)
+	Get(context.Context, *IdRequest) (*Question, error)
+	Delete(context.Context, *IdRequest) (*Void, error)
+	Random(context.Context, *RandomRequest) (*QuestionList, error)
+	Watch(*WatchRequest, Questions_WatchServer) error
+	BulkPut(Questions_BulkPutServer) error
+	Export(*Void, Questions_ExportServer) error
+	Update(context.Context, *UpdateRequest) (*Question, error)
+	Backup(*Void, Questions_BackupServer) error
+	Search(context.Context, *SearchRequest) (*QuestionList, error)
+	Reindex(context.Context, *Void) (*ReindexResult, error)
+	Duplicates(context.Context, *DuplicatesRequest) (*DuplicateClusters, error)
+	History(context.Context, *IdRequest) (*RevisionList, error)
+	Revert(context.Context, *RevertRequest) (*Question, error)
+	Trash(context.Context, *Void) (*TrashList, error)
+	Restore(context.Context, *IdRequest) (*Question, error)
+	Purge(context.Context, *PurgeRequest) (*PurgeResult, error)
+	AuditLog(context.Context, *AuditRequest) (*AuditEntryList, error)
+}
+
+func RegisterQuestionsServer(s *grpc.Server, srv QuestionsServer) {
+	s.RegisterService(&_Questions_serviceDesc, srv)
+}
+
+func _Questions_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).List(ctx, req.(*Filter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Put",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Put(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Get(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Delete(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Random_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Random(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Random",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Random(ctx, req.(*RandomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuestionsServer).Watch(m, &questionsWatchServer{stream})
+}
+
+type Questions_WatchServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type questionsWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *questionsWatchServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Questions_BulkPut_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(QuestionsServer).BulkPut(&questionsBulkPutServer{stream})
+}
+
+type Questions_BulkPutServer interface {
+	SendAndClose(*BulkPutResult) error
+	Recv() (*BulkPutRequest, error)
+	grpc.ServerStream
+}
+
+type questionsBulkPutServer struct {
+	grpc.ServerStream
+}
+
+func (x *questionsBulkPutServer) SendAndClose(m *BulkPutResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *questionsBulkPutServer) Recv() (*BulkPutRequest, error) {
+	m := new(BulkPutRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Questions_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Void)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuestionsServer).Export(m, &questionsExportServer{stream})
+}
+
+type Questions_ExportServer interface {
+	Send(*Question) error
+	grpc.ServerStream
+}
+
+type questionsExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *questionsExportServer) Send(m *Question) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Questions_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Void)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuestionsServer).Backup(m, &questionsBackupServer{stream})
+}
+
+type Questions_BackupServer interface {
+	Send(*BackupChunk) error
+	grpc.ServerStream
+}
+
+type questionsBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *questionsBackupServer) Send(m *BackupChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Questions_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Void)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Reindex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Reindex(ctx, req.(*Void))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Duplicates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DuplicatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Duplicates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Duplicates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Duplicates(ctx, req.(*DuplicatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).History(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Revert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Revert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Revert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Revert(ctx, req.(*RevertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Trash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Void)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Trash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Trash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Trash(ctx, req.(*Void))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Restore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Restore(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/Purge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).Purge(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Questions_AuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuestionsServer).AuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/question.Questions/AuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuestionsServer).AuditLog(ctx, req.(*AuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Questions_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "question.Questions",
+	HandlerType: (*QuestionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
 			MethodName: "List",
 			Handler:    _Questions_List_Handler,
 		},
@@ -330,32 +1717,172 @@ var _Questions_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _Questions_Delete_Handler,
 		},
+		{
+			MethodName: "Random",
+			Handler:    _Questions_Random_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Questions_Update_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Questions_Search_Handler,
+		},
+		{
+			MethodName: "Reindex",
+			Handler:    _Questions_Reindex_Handler,
+		},
+		{
+			MethodName: "Duplicates",
+			Handler:    _Questions_Duplicates_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _Questions_History_Handler,
+		},
+		{
+			MethodName: "Revert",
+			Handler:    _Questions_Revert_Handler,
+		},
+		{
+			MethodName: "Trash",
+			Handler:    _Questions_Trash_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _Questions_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _Questions_Purge_Handler,
+		},
+		{
+			MethodName: "AuditLog",
+			Handler:    _Questions_AuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Questions_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkPut",
+			Handler:       _Questions_BulkPut_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _Questions_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Backup",
+			Handler:       _Questions_Backup_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "question.proto",
 }
 
 func init() { proto.RegisterFile("question.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x92, 0xcd, 0x4a, 0x3b, 0x31,
-	0x14, 0xc5, 0x9b, 0x4e, 0x3a, 0xcc, 0xdc, 0xff, 0x9f, 0x22, 0x57, 0x29, 0xa1, 0xba, 0x18, 0xb2,
-	0x9a, 0x55, 0x5b, 0xea, 0x0b, 0x28, 0x88, 0xa5, 0xe0, 0x42, 0xb3, 0xf0, 0x01, 0x6a, 0x52, 0xb9,
-	0x50, 0x9b, 0x3a, 0x49, 0xc5, 0xf7, 0xf4, 0x85, 0x64, 0x32, 0x5f, 0x54, 0xc7, 0xdd, 0x3d, 0xf7,
-	0x83, 0xf3, 0x3b, 0x21, 0x30, 0x7e, 0x3f, 0x1a, 0xe7, 0xc9, 0xee, 0x67, 0x87, 0xc2, 0x7a, 0x8b,
-	0x49, 0xa3, 0xe5, 0x06, 0x92, 0xa7, 0xba, 0xc6, 0x31, 0x0c, 0x49, 0x0b, 0x96, 0xb1, 0x9c, 0xab,
-	0x21, 0x69, 0x44, 0xe0, 0xde, 0x7c, 0x7a, 0x31, 0xcc, 0x58, 0x9e, 0xaa, 0x50, 0xe3, 0x04, 0x62,
-	0x72, 0x2b, 0x6b, 0xb5, 0x88, 0x32, 0x96, 0x27, 0xaa, 0x56, 0x38, 0x85, 0x84, 0xdc, 0xed, 0x8b,
-	0xa7, 0x0f, 0x23, 0x78, 0x98, 0xb4, 0x5a, 0xde, 0xc0, 0xff, 0xc6, 0xe3, 0x81, 0x9c, 0xc7, 0x05,
-	0xa4, 0x8d, 0xbf, 0x13, 0x2c, 0x8b, 0xf2, 0x7f, 0x4b, 0x9c, 0xb5, 0x84, 0xcd, 0xaa, 0xea, 0x96,
-	0xe4, 0x01, 0xe2, 0x7b, 0xda, 0x79, 0x53, 0x9c, 0xf8, 0xb0, 0x53, 0x1f, 0xbc, 0x80, 0xd1, 0x8e,
-	0xde, 0xa8, 0x02, 0x1e, 0xa9, 0x4a, 0x94, 0xc4, 0x76, 0xbb, 0x75, 0xc6, 0x07, 0xe2, 0x91, 0xaa,
-	0x15, 0x5e, 0x41, 0x4a, 0xaf, 0x7b, 0x5b, 0x98, 0xb5, 0x76, 0x82, 0x67, 0x51, 0xce, 0x55, 0xd7,
-	0x90, 0x97, 0x90, 0xae, 0xb5, 0x32, 0x01, 0xe1, 0xe7, 0xc3, 0xc8, 0x18, 0xf8, 0xb3, 0x25, 0xbd,
-	0xfc, 0x62, 0x90, 0x36, 0xb8, 0x0e, 0x97, 0xc0, 0x43, 0xbc, 0xb3, 0x2e, 0x4b, 0x05, 0x3d, 0x9d,
-	0xfc, 0x4e, 0x57, 0x6e, 0xca, 0x01, 0xce, 0x21, 0x7a, 0x3c, 0x7a, 0xec, 0x89, 0x3f, 0xed, 0xe9,
-	0xc9, 0x01, 0x2e, 0x20, 0x5a, 0x19, 0x8f, 0xe7, 0xdd, 0xb0, 0xc5, 0xfc, 0xe3, 0x62, 0x0e, 0xf1,
-	0x9d, 0xd9, 0x19, 0x6f, 0xfa, 0x8f, 0xc6, 0x5d, 0xb3, 0xcc, 0x24, 0x07, 0x9b, 0x38, 0xfc, 0x91,
-	0xeb, 0xef, 0x00, 0x00, 0x00, 0xff, 0xff, 0x34, 0x17, 0x8d, 0x0d, 0x35, 0x02, 0x00, 0x00,
+	// 1499 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0xdb, 0x72, 0x13, 0x47,
+	0x13, 0xd6, 0xea, 0xb0, 0x92, 0xda, 0xb2, 0x7f, 0x33, 0x80, 0xd9, 0x5f, 0x50, 0xfc, 0xaa, 0x29,
+	0x2e, 0x54, 0x7f, 0x0a, 0xd9, 0x11, 0x09, 0x10, 0xa8, 0x4a, 0xe1, 0x83, 0x38, 0x14, 0x50, 0x65,
+	0x26, 0x06, 0x2e, 0x93, 0x45, 0x3b, 0x92, 0xb6, 0xbc, 0xde, 0x15, 0x33, 0xb3, 0xb6, 0x95, 0xbc,
+	0x42, 0x2e, 0x72, 0x91, 0x9b, 0x3c, 0x41, 0x6e, 0xf2, 0x00, 0xb9, 0xca, 0x63, 0xe4, 0x79, 0x52,
+	0x33, 0xb3, 0xb3, 0x07, 0x1d, 0x88, 0xcd, 0x9d, 0xba, 0xe7, 0x9b, 0xed, 0xee, 0xaf, 0x7b, 0xba,
+	0x5b, 0xb0, 0xf1, 0x31, 0xa6, 0x5c, 0xf8, 0x51, 0xd8, 0x9b, 0xb2, 0x48, 0x44, 0xa8, 0x61, 0xe4,
+	0x76, 0x67, 0x1c, 0x45, 0xe3, 0x80, 0x6e, 0x2b, 0xfd, 0x87, 0x78, 0xb4, 0x3d, 0xf2, 0x69, 0xe0,
+	0x7d, 0x7f, 0xe2, 0xf2, 0x63, 0x8d, 0x6d, 0xff, 0x6f, 0x1e, 0x21, 0xfc, 0x13, 0xca, 0x85, 0x7b,
+	0x32, 0x4d, 0x00, 0xb7, 0xe7, 0x01, 0x67, 0xcc, 0x9d, 0x4e, 0x29, 0xe3, 0xfa, 0x1c, 0xff, 0x61,
+	0x41, 0xe3, 0x4d, 0x62, 0x0f, 0x6d, 0x40, 0xd9, 0xf7, 0x1c, 0xab, 0x63, 0x75, 0xab, 0xa4, 0xec,
+	0x7b, 0x08, 0x41, 0x55, 0xd0, 0x73, 0xe1, 0x94, 0x3b, 0x56, 0xb7, 0x49, 0xd4, 0x6f, 0xb4, 0x05,
+	0xb6, 0xcf, 0x9f, 0x45, 0x91, 0xe7, 0x54, 0x3a, 0x56, 0xb7, 0x41, 0x12, 0x09, 0xb5, 0xa1, 0xe1,
+	0xf3, 0xdd, 0xa1, 0xf0, 0x4f, 0xa9, 0x53, 0x55, 0x27, 0xa9, 0x2c, 0xcf, 0x86, 0xae, 0xa0, 0xe3,
+	0x88, 0xcd, 0x9c, 0x9a, 0xfa, 0x56, 0x2a, 0x2b, 0x1b, 0xee, 0x98, 0x3b, 0x76, 0xa7, 0xa2, 0x6c,
+	0xb8, 0x63, 0x8e, 0x1c, 0xa8, 0x9f, 0x52, 0xc6, 0xfd, 0x28, 0x74, 0xea, 0xca, 0x19, 0x23, 0xe2,
+	0x11, 0xb4, 0x8c, 0xb7, 0xaf, 0x7c, 0x2e, 0xd0, 0x0e, 0x34, 0x0d, 0x5b, 0xdc, 0xb1, 0x3a, 0x95,
+	0xee, 0x5a, 0x1f, 0xf5, 0x52, 0x3e, 0x0d, 0x94, 0x64, 0x20, 0x74, 0x07, 0xd6, 0x43, 0x7a, 0x2e,
+	0x0e, 0xdd, 0x31, 0x3d, 0x8a, 0x8e, 0x69, 0x98, 0x04, 0x57, 0x54, 0xe2, 0x5f, 0xca, 0x60, 0x3f,
+	0xf5, 0x03, 0x41, 0x59, 0x21, 0x30, 0x6b, 0x2e, 0xb0, 0x6b, 0x50, 0x0b, 0xfc, 0x13, 0x5f, 0x33,
+	0x54, 0x23, 0x5a, 0x90, 0x14, 0x45, 0xa3, 0x11, 0xa7, 0x42, 0x51, 0x54, 0x23, 0x89, 0x84, 0x6e,
+	0x41, 0xd3, 0x1f, 0x87, 0x11, 0xa3, 0x2f, 0x3c, 0xee, 0x54, 0x3b, 0x95, 0x6e, 0x95, 0x64, 0x0a,
+	0x79, 0x3a, 0x4d, 0x9d, 0xd2, 0x2c, 0x65, 0x0a, 0x74, 0x1b, 0x20, 0xa1, 0xcc, 0xa7, 0x86, 0xac,
+	0x9c, 0x26, 0xa5, 0xb1, 0x5e, 0xa4, 0xd1, 0x0d, 0x82, 0x23, 0xa9, 0x6e, 0x28, 0xc7, 0x8d, 0x88,
+	0xfa, 0x69, 0x12, 0x9b, 0x1d, 0xab, 0xbb, 0xd6, 0x6f, 0xf7, 0x74, 0x99, 0xf4, 0x4c, 0x99, 0xf4,
+	0xf6, 0xa2, 0x28, 0x78, 0xe7, 0x06, 0x31, 0x35, 0x09, 0xc6, 0x67, 0xb0, 0x4e, 0xdc, 0xd0, 0x8b,
+	0x4e, 0x08, 0x55, 0x6c, 0xca, 0xe0, 0x87, 0x51, 0x1c, 0x0a, 0xc5, 0x4a, 0x8d, 0x68, 0xa1, 0x40,
+	0x57, 0x79, 0x8e, 0xae, 0x55, 0xb5, 0xf3, 0x49, 0x62, 0x70, 0x1f, 0x5a, 0xef, 0x5d, 0x31, 0x9c,
+	0x18, 0xbb, 0x18, 0x5a, 0x23, 0x26, 0xdd, 0x38, 0xf5, 0x55, 0x89, 0xe8, 0x7a, 0x2d, 0xe8, 0xf0,
+	0x9f, 0x16, 0xac, 0xed, 0x4f, 0xdc, 0x70, 0x4c, 0x07, 0xa7, 0x54, 0x7b, 0xc5, 0x8a, 0xf8, 0x54,
+	0x46, 0x3d, 0xa8, 0x8a, 0xd9, 0x54, 0x7b, 0xbb, 0xd1, 0x6f, 0x67, 0xe5, 0x93, 0xfb, 0x40, 0xef,
+	0x68, 0x36, 0xa5, 0x44, 0xe1, 0x50, 0x0f, 0xd2, 0x17, 0xaa, 0xe2, 0x58, 0x5e, 0x72, 0x29, 0x06,
+	0xdf, 0x85, 0xaa, 0xbc, 0x8d, 0xd6, 0xa0, 0xbe, 0x4f, 0x06, 0xbb, 0x47, 0x83, 0x83, 0xcd, 0x92,
+	0x14, 0xde, 0x1e, 0x1e, 0x28, 0xc1, 0x92, 0xc2, 0xc1, 0xe0, 0xd5, 0x40, 0x0a, 0x65, 0xfc, 0xb3,
+	0x05, 0x1b, 0x7b, 0x71, 0x70, 0x7c, 0x18, 0x0b, 0x13, 0x71, 0xde, 0xa2, 0xf5, 0xef, 0x16, 0xd1,
+	0x26, 0x54, 0x58, 0x74, 0xa6, 0x02, 0xaa, 0x12, 0xf9, 0x53, 0x32, 0xef, 0xb1, 0x19, 0x89, 0x43,
+	0xc3, 0xbc, 0x96, 0x50, 0x07, 0xd6, 0xa6, 0x8c, 0x72, 0xca, 0x4e, 0x13, 0xee, 0xe5, 0x61, 0x5e,
+	0x85, 0x1f, 0x41, 0x2b, 0xf1, 0x66, 0xc0, 0x58, 0xc4, 0xcc, 0xb7, 0xad, 0xec, 0xdb, 0x0e, 0xd4,
+	0x4f, 0x28, 0xe7, 0xee, 0x98, 0x26, 0x6f, 0xc9, 0x88, 0xf8, 0x77, 0x0b, 0xd6, 0xd3, 0x50, 0x78,
+	0x1c, 0x08, 0x89, 0x1d, 0x32, 0xea, 0x0a, 0x6a, 0xda, 0x8c, 0x11, 0xe5, 0x49, 0x3c, 0xf5, 0xd4,
+	0x89, 0xf6, 0xdb, 0x88, 0xa8, 0x07, 0x36, 0x95, 0xa6, 0xb9, 0x53, 0x51, 0x0f, 0x7c, 0x2b, 0x8b,
+	0x3d, 0xef, 0x19, 0x49, 0x50, 0xa8, 0x0f, 0x8d, 0x33, 0x97, 0x85, 0x7e, 0x38, 0xd6, 0xc5, 0xb4,
+	0xfa, 0x46, 0x8a, 0xc3, 0x3f, 0xc1, 0xfa, 0x5b, 0x65, 0xee, 0x73, 0x29, 0x7f, 0x04, 0xa0, 0xfd,
+	0x7d, 0xed, 0xf2, 0x63, 0xa7, 0xbc, 0xe2, 0x55, 0x3d, 0x95, 0xfd, 0x5b, 0x22, 0x48, 0x0e, 0x8d,
+	0x6f, 0x42, 0xf3, 0x85, 0x67, 0x0c, 0xcf, 0xf5, 0x60, 0x6c, 0x43, 0xf5, 0x5d, 0xe4, 0x7b, 0xf8,
+	0x35, 0xac, 0xed, 0xb9, 0xc3, 0xe3, 0x78, 0xba, 0x3f, 0x89, 0xc3, 0x63, 0xf9, 0xde, 0x3d, 0x57,
+	0xb8, 0x0a, 0xd8, 0x22, 0xea, 0xb7, 0xd4, 0x71, 0xff, 0x47, 0x9d, 0x85, 0x0a, 0x51, 0xbf, 0x65,
+	0xe2, 0xf9, 0xc4, 0xed, 0x7f, 0x7d, 0x5f, 0x25, 0xbe, 0x49, 0x12, 0x09, 0x73, 0x58, 0xff, 0x8e,
+	0xba, 0x2c, 0x7b, 0x55, 0xd7, 0xa0, 0xf6, 0x31, 0xa6, 0x6c, 0xa6, 0xbe, 0xd8, 0x24, 0x5a, 0xb8,
+	0x64, 0x83, 0xbb, 0x0d, 0x10, 0x85, 0xc1, 0xac, 0x30, 0x05, 0x72, 0x1a, 0x7c, 0x17, 0xd6, 0x09,
+	0xf5, 0x43, 0x8f, 0x9e, 0x27, 0xe5, 0x70, 0xab, 0xd8, 0xbe, 0x65, 0xcc, 0x99, 0x02, 0x7f, 0x09,
+	0x57, 0x0e, 0xe2, 0x69, 0xe0, 0xcb, 0x36, 0xc7, 0x8d, 0x9f, 0xb7, 0xa0, 0x29, 0x26, 0x8c, 0xf2,
+	0x49, 0x14, 0x68, 0x9a, 0x2c, 0x92, 0x29, 0xf0, 0x1d, 0xd8, 0x4c, 0xaf, 0xec, 0x07, 0x31, 0x97,
+	0x0d, 0x7c, 0x13, 0x2a, 0xbe, 0xa7, 0xa7, 0x43, 0x95, 0xc8, 0x9f, 0xf8, 0x25, 0x5c, 0x99, 0x47,
+	0x71, 0x74, 0x1f, 0x1a, 0xc3, 0xe4, 0x77, 0x32, 0x49, 0x72, 0xad, 0x60, 0x1e, 0x4e, 0x52, 0xac,
+	0x7c, 0xaf, 0x0d, 0x92, 0xf5, 0x92, 0xcb, 0x95, 0xcd, 0x43, 0x68, 0xa6, 0x13, 0x7b, 0x65, 0xd5,
+	0x1c, 0x19, 0x04, 0xc9, 0xc0, 0x32, 0x07, 0x6e, 0x2c, 0x26, 0x11, 0x33, 0x89, 0xd5, 0x12, 0x7e,
+	0x02, 0x2d, 0xe3, 0x8d, 0x99, 0x90, 0xa6, 0xd3, 0x2d, 0x99, 0x90, 0x06, 0x4a, 0x32, 0x10, 0x7e,
+	0x2f, 0xb3, 0x74, 0x4a, 0x99, 0x58, 0x51, 0x92, 0x2a, 0x05, 0xd1, 0xbb, 0x64, 0x40, 0xeb, 0xc7,
+	0x9a, 0x29, 0xf2, 0xc3, 0xbb, 0x52, 0x1c, 0xde, 0xbf, 0x59, 0xf0, 0x9f, 0x23, 0xe6, 0xf2, 0x09,
+	0xf5, 0xd2, 0x95, 0xe3, 0x33, 0x08, 0xf3, 0x68, 0x40, 0x05, 0xf5, 0x76, 0xc5, 0x45, 0x08, 0x4b,
+	0xc1, 0xd2, 0xeb, 0x44, 0xd8, 0x9b, 0x25, 0x9c, 0x65, 0x0a, 0x7c, 0x00, 0x4d, 0xe5, 0x9a, 0xe2,
+	0xec, 0xc1, 0xe2, 0x56, 0xf1, 0xdf, 0xcc, 0xab, 0xb9, 0x10, 0xf2, 0x15, 0xfb, 0x03, 0xb4, 0x0e,
+	0x63, 0x36, 0xa6, 0xab, 0x98, 0x7b, 0x02, 0xeb, 0xc6, 0x24, 0x1d, 0x45, 0x8c, 0x5e, 0x20, 0x82,
+	0xe2, 0x05, 0xfc, 0x05, 0xac, 0x25, 0x16, 0x2e, 0xf0, 0x80, 0xfe, 0x2e, 0x03, 0xec, 0xc6, 0x9e,
+	0x2f, 0x06, 0xa1, 0x60, 0xb3, 0x62, 0xb1, 0x59, 0x97, 0x2c, 0xb6, 0x13, 0x2a, 0x26, 0x91, 0x97,
+	0x74, 0xf8, 0x44, 0x92, 0xfa, 0xa1, 0x1b, 0x04, 0x34, 0x2d, 0x42, 0x2d, 0xc9, 0x46, 0x60, 0xbc,
+	0x78, 0xe1, 0xa9, 0x46, 0x50, 0x25, 0x39, 0x0d, 0xfa, 0x3f, 0xd8, 0x1f, 0x34, 0x01, 0xb5, 0x95,
+	0x39, 0x4f, 0x10, 0xa8, 0x0b, 0x35, 0x77, 0x24, 0x28, 0x73, 0xec, 0x95, 0x50, 0x0d, 0x40, 0x77,
+	0xa1, 0x7a, 0xec, 0x87, 0x9e, 0xda, 0x19, 0x37, 0xf2, 0x19, 0xcb, 0x38, 0xe8, 0xbd, 0xf4, 0x43,
+	0x8f, 0x28, 0x18, 0xfe, 0x06, 0xaa, 0x52, 0xba, 0xe0, 0x5c, 0x46, 0x00, 0xf6, 0xe1, 0x5b, 0xf2,
+	0x6c, 0x70, 0xb0, 0x59, 0xc1, 0xbf, 0x5a, 0xd0, 0x52, 0x1f, 0x35, 0x89, 0x2e, 0x06, 0x6c, 0x2d,
+	0x04, 0xbc, 0x03, 0x35, 0xee, 0x87, 0xc3, 0x8b, 0x24, 0x5c, 0x03, 0xb3, 0xce, 0x5b, 0xc9, 0x77,
+	0xde, 0xc2, 0x92, 0x58, 0x9d, 0x5b, 0x12, 0xf1, 0x08, 0x36, 0xb2, 0x50, 0x55, 0x25, 0xf7, 0xa0,
+	0x4e, 0x43, 0xa1, 0x76, 0x46, 0x5d, 0xc7, 0xd7, 0x96, 0xb1, 0x42, 0x0c, 0xe8, 0x62, 0xdb, 0x71,
+	0xff, 0xaf, 0x06, 0x34, 0xdf, 0xa4, 0x1b, 0x75, 0x1f, 0xaa, 0xca, 0xd6, 0x66, 0xf6, 0x69, 0xbd,
+	0x3a, 0xb7, 0xb7, 0x16, 0x73, 0x25, 0x91, 0xb8, 0x84, 0xb6, 0xa1, 0x72, 0x18, 0x0b, 0xb4, 0x24,
+	0x99, 0xed, 0x25, 0x3a, 0x5c, 0x42, 0x3b, 0x50, 0x79, 0x46, 0x05, 0xba, 0x9a, 0x1d, 0xa6, 0x23,
+	0x73, 0xc5, 0x8d, 0x6d, 0xb0, 0x0f, 0xd4, 0xd3, 0x59, 0x7e, 0x69, 0x23, 0x53, 0xaa, 0xf9, 0x5a,
+	0x42, 0x8f, 0xc1, 0xd6, 0x0b, 0x2e, 0xba, 0x91, 0x9d, 0x15, 0x56, 0xde, 0x4f, 0x04, 0xf4, 0x08,
+	0x6a, 0x6a, 0x49, 0x45, 0x39, 0x48, 0x7e, 0x6b, 0x6d, 0x5f, 0x5f, 0xba, 0x57, 0xe2, 0xd2, 0x8e,
+	0x85, 0x9e, 0x40, 0x3d, 0x59, 0x4b, 0x90, 0xb3, 0xb0, 0xa9, 0x98, 0xfb, 0x37, 0x96, 0x9c, 0xc8,
+	0x16, 0x80, 0x4b, 0x5d, 0x0b, 0xed, 0x80, 0x3d, 0x38, 0x9f, 0x46, 0x4c, 0xa0, 0xb9, 0xb0, 0x96,
+	0x73, 0xb3, 0x63, 0xa1, 0x07, 0x60, 0xeb, 0x85, 0x27, 0x1f, 0x6c, 0x61, 0x05, 0x5a, 0x41, 0xeb,
+	0x3d, 0xb0, 0xf5, 0x1e, 0xb2, 0x60, 0x2a, 0x17, 0x61, 0x6e, 0x53, 0x51, 0xd6, 0x1e, 0x83, 0xad,
+	0xb7, 0x8d, 0xbc, 0xb5, 0xc2, 0xfe, 0xf1, 0x09, 0x6a, 0xbf, 0x82, 0x7a, 0xb2, 0x35, 0x2c, 0x98,
+	0xcc, 0x27, 0x2a, 0xbf, 0x58, 0xe0, 0x12, 0x7a, 0x0e, 0x90, 0x2d, 0x0f, 0xe8, 0xe6, 0x92, 0x51,
+	0x6e, 0x56, 0x8a, 0xf6, 0xcd, 0xd5, 0x73, 0x9e, 0xe3, 0x12, 0x7a, 0x08, 0xf5, 0xe7, 0x3e, 0x17,
+	0xf2, 0xcf, 0xea, 0xd2, 0x4a, 0xda, 0x5a, 0x1c, 0xa7, 0x89, 0xe7, 0x0f, 0xc0, 0xd6, 0x93, 0xb4,
+	0x50, 0x51, 0xf9, 0xd9, 0xba, 0x82, 0xe4, 0x1e, 0xd4, 0xd4, 0x94, 0x59, 0x08, 0xf8, 0xea, 0xdc,
+	0x18, 0xca, 0x53, 0x24, 0x5d, 0xa4, 0x97, 0x79, 0x21, 0x0f, 0xa1, 0xa6, 0x66, 0x49, 0xbe, 0x66,
+	0xf3, 0xe3, 0xab, 0x7d, 0x7d, 0x41, 0x9f, 0x90, 0xfb, 0x2d, 0x34, 0x54, 0xf7, 0x78, 0x15, 0x8d,
+	0xf3, 0x97, 0xf3, 0x2d, 0xb1, 0xed, 0x2c, 0xeb, 0x34, 0xda, 0xdf, 0x0f, 0xb6, 0xea, 0x7b, 0xf7,
+	0xfe, 0x19, 0x00, 0x1e, 0x3d, 0xa7, 0xfe, 0xfb, 0x10, 0x00, 0x00,
 }
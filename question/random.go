@@ -0,0 +1,216 @@
+package question
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	poolsBucketName   = []byte("random_pools")
+	poolPositionsName = []byte("positions")
+	poolIdsName       = []byte("ids")
+	poolSizeKey       = []byte("size")
+)
+
+// A random pool is a nested bucket of the random_pools bucket which keeps
+// IDs of questions with the same isActive and isGood flags as a dense array:
+// positions maps 0..size-1 to IDs and ids maps IDs back to their positions.
+// It allows to pick a uniformly random question without walking the whole bucket
+
+// poolName returns a name of the random pool for the given flags
+func poolName(isActive, isGood bool) []byte {
+	return []byte(fmt.Sprintf("active=%t,good=%t", isActive, isGood))
+}
+
+// poolSize returns a number of questions in the pool
+func poolSize(pool *bolt.Bucket) uint64 {
+	v := pool.Get(poolSizeKey)
+	if v == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(v)
+}
+
+// poolAdd appends question ID to the end of its random pool
+func poolAdd(tx *bolt.Tx, q *Question) error {
+	pools, err := tx.CreateBucketIfNotExists(poolsBucketName)
+	if err != nil {
+		return err
+	}
+
+	pool, err := pools.CreateBucketIfNotExists(poolName(q.IsActive, q.IsGood))
+	if err != nil {
+		return err
+	}
+
+	positions, err := pool.CreateBucketIfNotExists(poolPositionsName)
+	if err != nil {
+		return err
+	}
+
+	ids, err := pool.CreateBucketIfNotExists(poolIdsName)
+	if err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(q.Id)
+	if ids.Get(key) != nil {
+		return nil
+	}
+
+	size := poolSize(pool)
+	pos := utils.Uinttob(size)
+	if err := positions.Put(pos, key); err != nil {
+		return err
+	}
+
+	if err := ids.Put(key, pos); err != nil {
+		return err
+	}
+
+	return pool.Put(poolSizeKey, utils.Uinttob(size+1))
+}
+
+// poolRemove removes question ID from its random pool
+// moving the last ID of the pool into the freed position
+func poolRemove(tx *bolt.Tx, q *Question) error {
+	pools := tx.Bucket(poolsBucketName)
+	if pools == nil {
+		return nil
+	}
+
+	pool := pools.Bucket(poolName(q.IsActive, q.IsGood))
+	if pool == nil {
+		return nil
+	}
+
+	positions := pool.Bucket(poolPositionsName)
+	ids := pool.Bucket(poolIdsName)
+	key := utils.Uinttob(q.Id)
+	pos := ids.Get(key)
+	if pos == nil {
+		return nil
+	}
+	pos = append([]byte{}, pos...)
+
+	size := poolSize(pool)
+	lastPos := utils.Uinttob(size - 1)
+	lastKey := append([]byte{}, positions.Get(lastPos)...)
+
+	if err := positions.Put(pos, lastKey); err != nil {
+		return err
+	}
+
+	if err := ids.Put(lastKey, pos); err != nil {
+		return err
+	}
+
+	if err := positions.Delete(lastPos); err != nil {
+		return err
+	}
+
+	if err := ids.Delete(key); err != nil {
+		return err
+	}
+
+	return pool.Put(poolSizeKey, utils.Uinttob(size-1))
+}
+
+// Random returns up to req.Count uniformly random questions
+// with the requested flags, skipping ignored IDs
+func (qs *Storage) Random(req *RandomRequest) (*QuestionList, error) {
+	if req.Count < 0 {
+		return nil, invalidArgument("count must not be negative")
+	}
+
+	list := &QuestionList{}
+	if req.Count == 0 {
+		return list, nil
+	}
+
+	ignoreIds := make(map[uint64]bool, len(req.IgnoreIds))
+	for _, id := range req.IgnoreIds {
+		ignoreIds[id] = true
+	}
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(questionsBucketName)
+		pools := tx.Bucket(poolsBucketName)
+		if b == nil || pools == nil {
+			return nil
+		}
+
+		pool := pools.Bucket(poolName(req.IsActive, req.IsGood))
+		if pool == nil {
+			return nil
+		}
+
+		positions := pool.Bucket(poolPositionsName)
+		size := poolSize(pool)
+
+		// A lazy Fisher-Yates shuffle of the positions: only swapped
+		// positions are kept in memory, so the cost depends on the number
+		// of picked and ignored questions instead of the pool size
+		swapped := make(map[uint64]uint64)
+		at := func(i uint64) uint64 {
+			if v, ok := swapped[i]; ok {
+				return v
+			}
+			return i
+		}
+
+		for i := uint64(0); i < size && int32(len(list.Questions)) < req.Count; i++ {
+			j := i + uint64(random.Int63n(int64(size-i)))
+			pos := at(j)
+			swapped[j] = at(i)
+
+			key := positions.Get(utils.Uinttob(pos))
+			if ignoreIds[binary.BigEndian.Uint64(key)] {
+				continue
+			}
+
+			q := &Question{}
+			if err := proto.Unmarshal(b.Get(key), q); err != nil {
+				return err
+			}
+
+			list.Questions = append(list.Questions, q)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// lockedSource is a rand.Source which is safe for concurrent use
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+var random = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
@@ -0,0 +1,367 @@
+package question
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	searchTermsName = []byte("search_terms")
+	searchDocsName  = []byte("search_docs")
+	searchMetaName  = []byte("search_meta")
+
+	searchDocsKey   = []byte("docs")
+	searchLengthKey = []byte("length")
+)
+
+// The full-text index is an inverted index of question texts:
+// search_terms has a nested bucket per term which maps question IDs
+// to the term frequency, search_docs maps question IDs to their number
+// of terms and search_meta keeps the number of indexed questions
+// and the total number of terms for ranking
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Page sizes of search results
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// maxPrefixTerms is a number of index terms a prefix term may expand to,
+// so a short prefix doesn't read postings of the whole index
+const maxPrefixTerms = 256
+
+// Searcher is implemented by stores with a full-text index
+type Searcher interface {
+	// Search returns questions matching all words of the query, the best first
+	Search(req *SearchRequest) (*QuestionList, error)
+
+	// Reindex rebuilds the index and returns the number of indexed questions
+	Reindex() (uint64, error)
+}
+
+var _ Searcher = (*Storage)(nil)
+
+// searchAdd puts terms of the question text into the full-text index
+func searchAdd(tx *bolt.Tx, q *Question) error {
+	docs, err := tx.CreateBucketIfNotExists(searchDocsName)
+	if err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(q.Id)
+	if docs.Get(key) != nil {
+		return nil
+	}
+
+	qterms := terms(q.Text)
+	if len(qterms) == 0 {
+		return nil
+	}
+
+	parent, err := tx.CreateBucketIfNotExists(searchTermsName)
+	if err != nil {
+		return err
+	}
+
+	for term, tf := range frequencies(qterms) {
+		b, err := parent.CreateBucketIfNotExists([]byte(term))
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(key, uint32tob(tf)); err != nil {
+			return err
+		}
+	}
+
+	if err := docs.Put(key, uint32tob(uint32(len(qterms)))); err != nil {
+		return err
+	}
+
+	return updateSearchMeta(tx, 1, int64(len(qterms)))
+}
+
+// searchRemove removes terms of the question text from the full-text index
+func searchRemove(tx *bolt.Tx, q *Question) error {
+	docs := tx.Bucket(searchDocsName)
+	if docs == nil {
+		return nil
+	}
+
+	key := utils.Uinttob(q.Id)
+	length := docs.Get(key)
+	if length == nil {
+		return nil
+	}
+	n := binary.BigEndian.Uint32(length)
+
+	if parent := tx.Bucket(searchTermsName); parent != nil {
+		for term := range frequencies(terms(q.Text)) {
+			b := parent.Bucket([]byte(term))
+			if b == nil {
+				continue
+			}
+
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+
+			if k, _ := b.Cursor().First(); k == nil {
+				if err := parent.DeleteBucket([]byte(term)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := docs.Delete(key); err != nil {
+		return err
+	}
+
+	return updateSearchMeta(tx, -1, -int64(n))
+}
+
+// updateSearchMeta changes the number of indexed questions and terms
+func updateSearchMeta(tx *bolt.Tx, docs, length int64) error {
+	meta, err := tx.CreateBucketIfNotExists(searchMetaName)
+	if err != nil {
+		return err
+	}
+
+	if err := meta.Put(searchDocsKey, utils.Uinttob(uint64(int64(metaValue(meta, searchDocsKey))+docs))); err != nil {
+		return err
+	}
+
+	return meta.Put(searchLengthKey, utils.Uinttob(uint64(int64(metaValue(meta, searchLengthKey))+length)))
+}
+
+func metaValue(meta *bolt.Bucket, key []byte) uint64 {
+	if meta == nil {
+		return 0
+	}
+
+	v := meta.Get(key)
+	if v == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(v)
+}
+
+// frequencies counts occurrences of every term
+func frequencies(terms []string) map[string]uint32 {
+	result := make(map[string]uint32, len(terms))
+	for _, term := range terms {
+		result[term]++
+	}
+
+	return result
+}
+
+func uint32tob(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// Search returns questions which contain every term of the query
+// ranked by BM25, questions with equal scores are ordered by ID
+func (qs *Storage) Search(req *SearchRequest) (*QuestionList, error) {
+	if req.Limit < 0 || req.Offset < 0 {
+		return nil, invalidArgument("limit and offset must not be negative")
+	}
+
+	limit := req.Limit
+	switch {
+	case limit == 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	query := parseQuery(req.Query)
+	if len(query) == 0 {
+		return nil, invalidArgument("query must contain at least one word")
+	}
+
+	list := &QuestionList{
+		Questions: make([]*Question, 0, limit),
+	}
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(questionsBucketName)
+		parent := tx.Bucket(searchTermsName)
+		docs := tx.Bucket(searchDocsName)
+		if b == nil || parent == nil || docs == nil {
+			return nil
+		}
+
+		meta := tx.Bucket(searchMetaName)
+		total := float64(metaValue(meta, searchDocsKey))
+		avgLength := float64(metaValue(meta, searchLengthKey)) / math.Max(total, 1)
+
+		scores := make(map[uint64]float64)
+		matched := make(map[uint64]int)
+
+		for _, qt := range query {
+			seen := make(map[uint64]bool)
+
+			buckets, err := termBuckets(parent, qt)
+			if err != nil {
+				return err
+			}
+
+			for _, tb := range buckets {
+				type posting struct {
+					id uint64
+					tf float64
+				}
+
+				var postings []posting
+				tb.ForEach(func(k, v []byte) error {
+					postings = append(postings, posting{
+						id: binary.BigEndian.Uint64(k),
+						tf: float64(binary.BigEndian.Uint32(v)),
+					})
+					return nil
+				})
+
+				df := float64(len(postings))
+				idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+
+				for _, p := range postings {
+					length := float64(binary.BigEndian.Uint32(docs.Get(utils.Uinttob(p.id))))
+					scores[p.id] += idf * p.tf * (bm25K1 + 1) / (p.tf + bm25K1*(1-bm25B+bm25B*length/avgLength))
+
+					if !seen[p.id] {
+						seen[p.id] = true
+						matched[p.id]++
+					}
+				}
+			}
+		}
+
+		var found []uint64
+		for id, n := range matched {
+			if n == len(query) {
+				found = append(found, id)
+			}
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			if scores[found[i]] != scores[found[j]] {
+				return scores[found[i]] > scores[found[j]]
+			}
+			return found[i] < found[j]
+		})
+
+		var offset int32
+		for _, id := range found {
+			if int32(len(list.Questions)) >= limit {
+				break
+			}
+
+			q := &Question{}
+			if err := proto.Unmarshal(b.Get(utils.Uinttob(id)), q); err != nil {
+				return err
+			}
+
+			if req.OnlyActive && !q.IsActive {
+				continue
+			}
+
+			if offset < req.Offset {
+				offset++
+				continue
+			}
+
+			list.Questions = append(list.Questions, q)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// termBuckets returns the posting buckets of a query term,
+// all terms starting with a prefix term are expanded.
+// A prefix matching more than maxPrefixTerms terms is rejected
+func termBuckets(parent *bolt.Bucket, qt queryTerm) ([]*bolt.Bucket, error) {
+	if !qt.prefix {
+		if b := parent.Bucket([]byte(qt.term)); b != nil {
+			return []*bolt.Bucket{b}, nil
+		}
+		return nil, nil
+	}
+
+	var buckets []*bolt.Bucket
+	prefix := []byte(qt.term)
+
+	c := parent.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if len(buckets) == maxPrefixTerms {
+			return nil, invalidArgument("%s* matches more than %d words, make it longer", qt.term, maxPrefixTerms)
+		}
+
+		if b := parent.Bucket(k); b != nil {
+			buckets = append(buckets, b)
+		}
+	}
+
+	return buckets, nil
+}
+
+// Reindex drops the full-text index and builds it again from all questions
+func (qs *Storage) Reindex() (uint64, error) {
+	var count uint64
+
+	err := qs.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{searchTermsName, searchDocsName, searchMetaName} {
+			if tx.Bucket(name) == nil {
+				continue
+			}
+
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			q := &Question{}
+			if err := proto.Unmarshal(v, q); err != nil {
+				return err
+			}
+
+			count++
+			return searchAdd(tx, q)
+		})
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
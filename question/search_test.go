@@ -0,0 +1,145 @@
+package question_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+)
+
+// putTexts saves questions with the texts and returns their IDs
+func putTexts(t *testing.T, s *question.Storage, texts ...string) []uint64 {
+	var ids []uint64
+	for _, text := range texts {
+		change, err := s.Put(question.Question{Text: text, IsActive: true})
+		if err != nil {
+			t.Fatalf("Couldn't put %q: %v", text, err)
+		}
+		ids = append(ids, change.After.Id)
+	}
+
+	return ids
+}
+
+// searchIDs returns IDs of the found questions in order
+func searchIDs(t *testing.T, s *question.Storage, req *question.SearchRequest) []uint64 {
+	list, err := s.Search(req)
+	if err != nil {
+		t.Fatalf("Search(%q) failed: %v", req.Query, err)
+	}
+
+	var ids []uint64
+	for _, q := range list.Questions {
+		ids = append(ids, q.Id)
+	}
+
+	return ids
+}
+
+func TestSearchRanking(t *testing.T) {
+	s := newBoltStorage(t)
+
+	ids := putTexts(t, s,
+		"Which mountain stands near the biggest volcano of the island and the old city?",
+		"Which volcano erupted last year?",
+		"Which volcano erupted, and which volcano slept?",
+		"Which river flows from the mountain?",
+		"Which volcano erupted last year?",
+	)
+
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		// More occurrences rank higher, then shorter texts, then lower IDs
+		{"volcano", []uint64{ids[2], ids[1], ids[4], ids[0]}},
+		// Shorter texts outweigh the repeated volcano when both words count
+		{"volcanoes erupting", []uint64{ids[1], ids[4], ids[2]}},
+		// With one occurrence each the shorter text ranks higher
+		{"mountain", []uint64{ids[3], ids[0]}},
+		{"volc*", []uint64{ids[2], ids[1], ids[4], ids[0]}},
+		{"volcano river", nil},
+	}
+
+	for _, test := range tests {
+		got := searchIDs(t, s, &question.SearchRequest{Query: test.query})
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchStemming(t *testing.T) {
+	s := newBoltStorage(t)
+
+	ids := putTexts(t, s,
+		"Какой город является столицей Франции?",
+		"Which capitals are on the Danube?",
+		"Где находятся столицы Европы?",
+	)
+
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		{"столица", []uint64{ids[2], ids[0]}},
+		{"Столицы Франции", []uint64{ids[0]}},
+		{"городов", []uint64{ids[0]}},
+		{"capital", []uint64{ids[1]}},
+		{"DANUBE capitals", []uint64{ids[1]}},
+		{"стол*", []uint64{ids[2], ids[0]}},
+	}
+
+	for _, test := range tests {
+		got := searchIDs(t, s, &question.SearchRequest{Query: test.query})
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	s := newBoltStorage(t)
+
+	var texts []string
+	for i := 0; i < 120; i++ {
+		texts = append(texts, fmt.Sprintf("Quiz question number %d", i))
+	}
+	putTexts(t, s, texts...)
+
+	if got := searchIDs(t, s, &question.SearchRequest{Query: "quiz"}); len(got) != 20 {
+		t.Errorf("Search() without a limit returned %d questions, want 20", len(got))
+	}
+
+	if got := searchIDs(t, s, &question.SearchRequest{Query: "quiz", Limit: 1000}); len(got) != 100 {
+		t.Errorf("Search() with a limit of 1000 returned %d questions, want 100", len(got))
+	}
+
+	if got := searchIDs(t, s, &question.SearchRequest{Query: "quiz", Limit: 10, Offset: 115}); len(got) != 5 {
+		t.Errorf("Search() of the last page returned %d questions, want 5", len(got))
+	}
+
+	if _, err := s.Search(&question.SearchRequest{Query: "quiz", Limit: -1}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Search() with a negative limit error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestSearchLimitsPrefixes(t *testing.T) {
+	s := newBoltStorage(t)
+
+	var words []string
+	for i := 0; i < 300; i++ {
+		words = append(words, fmt.Sprintf("zz%d", i))
+	}
+	ids := putTexts(t, s, strings.Join(words, " "))
+
+	if _, err := s.Search(&question.SearchRequest{Query: "zz*"}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Search(zz*) matching 300 words error = %v, want ErrInvalidArgument", err)
+	}
+
+	if got := searchIDs(t, s, &question.SearchRequest{Query: "zz1*"}); fmt.Sprint(got) != fmt.Sprint(ids) {
+		t.Errorf("Search(zz1*) = %v, want %v", got, ids)
+	}
+}
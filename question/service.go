@@ -1,52 +1,579 @@
 package question
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	fmt "fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/almostmoore/gbquestion/auth"
 	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
+// bulkPutBatchSize is a number of questions written or checked in one transaction by BulkPut
+const bulkPutBatchSize = 500
+
 // RPCService is a simple grpc question service
 type RPCService struct {
-	storage *Storage
+	storage Store
+	watcher *Watcher
+	writes  *sync.Mutex
 }
 
 // NewRPCService returns a new service
-func NewRPCService(s *Storage) *RPCService {
+func NewRPCService(s Store) *RPCService {
 	return &RPCService{
 		storage: s,
+		watcher: NewWatcher(),
+		writes:  &sync.Mutex{},
+	}
+}
+
+// write runs a change of the storage and publishes it to watchers.
+// Writes are serialized, so events are published in the order of commits.
+// IDs of questions similar to the written one are sent in the SimilarKey
+// header of a successful write or the trailer of a rejected one
+func (s RPCService) write(ctx context.Context, fn func() (*Change, error)) (*Change, error) {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	change, err := fn()
+
+	var duplicate *DuplicateError
+	if errors.As(err, &duplicate) {
+		grpc.SetTrailer(ctx, metadata.Pairs(SimilarKey, similarIds(duplicate.Similar)))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(change.Similar) > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs(SimilarKey, similarIds(change.Similar)))
+	}
+
+	s.watcher.Publish(change)
+	return change, nil
+}
+
+// store returns the storage which records changes as made by the caller
+func (s RPCService) store(ctx context.Context) Store {
+	if as, ok := s.storage.(ActorStore); ok {
+		return as.As(actor(ctx))
+	}
+
+	return s.storage
+}
+
+// checkedStore returns the storage which records changes as made by the caller
+// and checks written questions for duplicates as the request tells
+func (s RPCService) checkedStore(ctx context.Context) (Store, error) {
+	mode := DuplicatesWarn
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(DuplicatesKey); len(values) > 0 {
+			mode = values[0]
+		}
+	}
+
+	switch mode {
+	case DuplicatesAllow:
+		return s.store(ctx), nil
+	case DuplicatesWarn, DuplicatesReject:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown %s mode %q", DuplicatesKey, mode)
+	}
+
+	checker, ok := s.store(ctx).(DuplicateChecker)
+	if !ok {
+		if mode == DuplicatesReject {
+			return nil, toStatus(ErrUnsupported, "Couldn't look for similar questions")
+		}
+		return s.store(ctx), nil
+	}
+
+	return checker.CheckDuplicates(DefaultSimilarity, mode == DuplicatesReject), nil
+}
+
+// actor describes the caller by the name of its identity
+// or by its address if it is anonymous and the called RPC
+func actor(ctx context.Context) Actor {
+	var a Actor
+	if method, ok := grpc.Method(ctx); ok {
+		a.Method = path.Base(method)
+	}
+
+	if id, ok := auth.FromContext(ctx); ok && id.Name != "" {
+		a.Name = id.Name
+	} else if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		a.Name = p.Addr.String()
 	}
+
+	return a
 }
 
 // List func returns a filtered list of questions
 func (s RPCService) List(ctx context.Context, filter *Filter) (*QuestionList, error) {
 	list, err := s.storage.Filter(filter)
 	if err != nil {
-		return nil, fmt.Errorf("Coudln't get questions from the storage: %v", err)
+		return nil, toStatus(err, "Coudln't get questions from the storage")
 	}
 
-	return &QuestionList{
-		Questions: list,
-	}, nil
+	return list, nil
 }
 
+// Near-duplicate detection on writes is set by the DuplicatesKey request metadata:
+// DuplicatesWarn saves the question and lists IDs of similar ones in the SimilarKey
+// response header, DuplicatesReject fails with AlreadyExists and lists them
+// in the SimilarKey trailer and DuplicatesAllow skips the check.
+// BulkPut reports similar questions as warnings or errors of rows instead
+const (
+	DuplicatesKey = "x-duplicates"
+	SimilarKey    = "x-similar-questions"
+
+	DuplicatesWarn   = "warn"
+	DuplicatesReject = "reject"
+	DuplicatesAllow  = "allow"
+)
+
 // Put func saves a question
 func (s RPCService) Put(ctx context.Context, q *Question) (*Question, error) {
-	id, err := s.storage.Put(*q)
+	storage, err := s.checkedStore(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("Couldn't save a message: %v", err)
+		return nil, err
 	}
 
-	q.Id = id
-	return q, nil
+	change, err := s.write(ctx, func() (*Change, error) { return storage.Put(*q) })
+	if err != nil {
+		return nil, toStatus(err, "Couldn't save a message")
+	}
+
+	return change.After, nil
+}
+
+// similarIds returns a comma separated list of IDs
+func similarIds(similar []Similarity) string {
+	ids := make([]string, 0, len(similar))
+	for _, sim := range similar {
+		ids = append(ids, strconv.FormatUint(sim.Id, 10))
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// Update func changes the fields of a question listed in the update mask
+func (s RPCService) Update(ctx context.Context, req *UpdateRequest) (*Question, error) {
+	if req.Question == nil || req.UpdateMask == nil {
+		return nil, status.Error(codes.InvalidArgument, "Question and update mask are required")
+	}
+
+	storage, err := s.checkedStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.write(ctx, func() (*Change, error) { return storage.Update(req.Question, req.UpdateMask.Paths) })
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't update question %d", req.Question.Id))
+	}
+
+	return change.After, nil
 }
 
 // Get func returns a question by ID
 func (s RPCService) Get(ctx context.Context, req *IdRequest) (*Question, error) {
-	return s.storage.Get(req.Id)
+	q, err := s.storage.Get(req.Id)
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't get question %d", req.Id))
+	}
+
+	return q, nil
 }
 
 // Delete func delete question by ID
 func (s RPCService) Delete(ctx context.Context, req *IdRequest) (*Void, error) {
-	return &Void{}, s.storage.Delete(req.Id)
+	_, err := s.write(ctx, func() (*Change, error) { return s.store(ctx).Delete(req.Id) })
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't delete question %d", req.Id))
+	}
+
+	return &Void{}, nil
+}
+
+// Random func returns random questions
+func (s RPCService) Random(ctx context.Context, req *RandomRequest) (*QuestionList, error) {
+	list, err := s.storage.Random(req)
+	if err != nil {
+		return nil, toStatus(err, "Couldn't get random questions from the storage")
+	}
+
+	return list, nil
+}
+
+// Watch func streams changes of questions until the client goes away
+func (s RPCService) Watch(req *WatchRequest, stream Questions_WatchServer) error {
+	backlog, events, cancel, err := s.watcher.Subscribe(req.FromRevision)
+	if err != nil {
+		return toStatus(err, fmt.Sprintf("Couldn't resume from revision %d", req.FromRevision))
+	}
+	defer cancel()
+
+	for _, e := range backlog {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case e, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "Client is too slow, resume from the last received revision")
+			}
+
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// BulkPut func saves a stream of questions in batches.
+// Options are taken from the first request of the stream.
+// Every batch is written in one transaction, rows which can't be written are skipped
+// and reported in the result. Batches written before a failure stay written.
+// A dry run checks the same batches and rolls each of them back, so a row
+// is checked against the stored questions and earlier rows of its batch only
+func (s RPCService) BulkPut(stream Questions_BulkPutServer) error {
+	result := &BulkPutResult{}
+	var batch []bulkRow
+	var first *BulkPutRequest
+
+	storage, err := s.checkedStore(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	flush := func() {
+		s.writes.Lock()
+		defer s.writes.Unlock()
+
+		questions := make([]Question, len(batch))
+		for i, row := range batch {
+			questions[i] = row.question
+		}
+
+		changes, rowErrs, err := storage.PutMany(questions, first.DryRun)
+		if err != nil {
+			for _, row := range batch {
+				result.Errors = append(result.Errors, &BulkPutError{Row: row.row, Message: err.Error()})
+			}
+			batch = nil
+			return
+		}
+
+		for _, rowErr := range rowErrs {
+			result.Errors = append(result.Errors, &BulkPutError{Row: batch[rowErr.Index].row, Message: rowErr.Err.Error()})
+		}
+
+		for i, change := range changes {
+			if change == nil {
+				continue
+			}
+
+			if change.Before == nil {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+
+			if len(change.Similar) > 0 {
+				result.Warnings = append(result.Warnings, &BulkPutError{Row: batch[i].row, Message: describeSimilar(change.Similar)})
+			}
+
+			if !first.DryRun {
+				s.watcher.Publish(change)
+			}
+		}
+
+		batch = nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if first == nil {
+			first = req
+		}
+
+		q := Question{}
+		if req.Question != nil {
+			q = *req.Question
+		}
+
+		if !first.PreserveIds {
+			q.Id = 0
+		}
+
+		// Imported versions belong to another database, so rows are written unconditionally
+		q.Version = 0
+
+		if err := validate(&q); err != nil {
+			result.Errors = append(result.Errors, &BulkPutError{Row: req.Row, Message: err.Error()})
+			continue
+		}
+
+		batch = append(batch, bulkRow{row: req.Row, question: q})
+		if len(batch) == bulkPutBatchSize {
+			flush()
+		}
+	}
+
+	if len(batch) > 0 {
+		flush()
+	}
+
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+	sort.Slice(result.Warnings, func(i, j int) bool { return result.Warnings[i].Row < result.Warnings[j].Row })
+
+	return stream.SendAndClose(result)
+}
+
+// bulkRow is a question of BulkPut with its row in the imported file
+type bulkRow struct {
+	row      uint64
+	question Question
+}
+
+// Export func streams all questions
+func (s RPCService) Export(req *Void, stream Questions_ExportServer) error {
+	err := s.storage.ForEach(stream.Send)
+	if err != nil {
+		return toStatus(err, "Couldn't export questions")
+	}
+
+	return nil
+}
+
+// Search func finds questions by words of their text
+func (s RPCService) Search(ctx context.Context, req *SearchRequest) (*QuestionList, error) {
+	searcher, ok := s.storage.(Searcher)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't search questions")
+	}
+
+	list, err := searcher.Search(req)
+	if err != nil {
+		return nil, toStatus(err, "Couldn't search questions")
+	}
+
+	return list, nil
+}
+
+// Reindex func rebuilds the full-text index
+func (s RPCService) Reindex(ctx context.Context, req *Void) (*ReindexResult, error) {
+	searcher, ok := s.storage.(Searcher)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't rebuild the search index")
+	}
+
+	n, err := searcher.Reindex()
+	if err != nil {
+		return nil, toStatus(err, "Couldn't rebuild the search index")
+	}
+
+	return &ReindexResult{Questions: n}, nil
+}
+
+// Duplicates func returns clusters of similar questions
+func (s RPCService) Duplicates(ctx context.Context, req *DuplicatesRequest) (*DuplicateClusters, error) {
+	finder, ok := s.storage.(DuplicateFinder)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't look for duplicates")
+	}
+
+	threshold := req.Threshold
+	if threshold == 0 {
+		threshold = DefaultSimilarity
+	}
+
+	clusters, err := finder.Duplicates(threshold)
+	if err != nil {
+		return nil, toStatus(err, "Couldn't look for duplicates")
+	}
+
+	result := &DuplicateClusters{
+		Clusters: make([]*DuplicateCluster, 0, len(clusters)),
+	}
+	for _, ids := range clusters {
+		result.Clusters = append(result.Clusters, &DuplicateCluster{Ids: ids})
+	}
+
+	return result, nil
+}
+
+// History func returns versions of a question, the oldest first
+func (s RPCService) History(ctx context.Context, req *IdRequest) (*RevisionList, error) {
+	historian, ok := s.storage.(Historian)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, fmt.Sprintf("Couldn't get history of question %d", req.Id))
+	}
+
+	revisions, err := historian.History(req.Id)
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't get history of question %d", req.Id))
+	}
+
+	return &RevisionList{Revisions: revisions}, nil
+}
+
+// Revert func writes a question as it was at an earlier version
+func (s RPCService) Revert(ctx context.Context, req *RevertRequest) (*Question, error) {
+	storage, err := s.checkedStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	historian, ok := storage.(Historian)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, fmt.Sprintf("Couldn't revert question %d", req.Id))
+	}
+
+	change, err := s.write(ctx, func() (*Change, error) { return historian.Revert(req) })
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't revert question %d to version %d", req.Id, req.ToVersion))
+	}
+
+	return change.After, nil
+}
+
+// Trash func returns deleted questions
+func (s RPCService) Trash(ctx context.Context, req *Void) (*TrashList, error) {
+	trasher, ok := s.storage.(Trasher)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't get deleted questions")
+	}
+
+	questions, err := trasher.Trash()
+	if err != nil {
+		return nil, toStatus(err, "Couldn't get deleted questions")
+	}
+
+	return &TrashList{Questions: questions}, nil
+}
+
+// Restore func moves a question from the trash back
+func (s RPCService) Restore(ctx context.Context, req *IdRequest) (*Question, error) {
+	storage, err := s.checkedStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trasher, ok := storage.(Trasher)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, fmt.Sprintf("Couldn't restore question %d", req.Id))
+	}
+
+	change, err := s.write(ctx, func() (*Change, error) { return trasher.Restore(req.Id) })
+	if err != nil {
+		return nil, toStatus(err, fmt.Sprintf("Couldn't restore question %d", req.Id))
+	}
+
+	return change.After, nil
+}
+
+// Purge func removes questions from the trash for good
+func (s RPCService) Purge(ctx context.Context, req *PurgeRequest) (*PurgeResult, error) {
+	trasher, ok := s.store(ctx).(Trasher)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't purge the trash")
+	}
+
+	n, err := trasher.Purge(req)
+	if err != nil {
+		return nil, toStatus(err, "Couldn't purge the trash")
+	}
+
+	return &PurgeResult{Questions: n}, nil
+}
+
+// AuditLog func returns a page of the log of changes
+func (s RPCService) AuditLog(ctx context.Context, req *AuditRequest) (*AuditEntryList, error) {
+	auditor, ok := s.storage.(Auditor)
+	if !ok {
+		return nil, toStatus(ErrUnsupported, "Couldn't read the audit log")
+	}
+
+	list, err := auditor.AuditLog(req)
+	if err != nil {
+		return nil, toStatus(err, "Couldn't read the audit log")
+	}
+
+	return list, nil
+}
+
+// backupChunkSize is a maximum size of data in one backup chunk
+const backupChunkSize = 64 << 10
+
+// Backup func streams a consistent snapshot of the database.
+// The last chunk carries the size and SHA-256 of the snapshot
+func (s RPCService) Backup(req *Void, stream Questions_BackupServer) error {
+	backuper, ok := s.storage.(Backuper)
+	if !ok {
+		return toStatus(ErrUnsupported, "Couldn't back up questions")
+	}
+
+	h := sha256.New()
+	w := bufio.NewWriterSize(io.MultiWriter(h, chunkWriter(stream.Send)), backupChunkSize)
+
+	n, err := backuper.Backup(w)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err != nil {
+		return toStatus(err, "Couldn't back up questions")
+	}
+
+	return stream.Send(&BackupChunk{Size: n, Sha256: hex.EncodeToString(h.Sum(nil))})
+}
+
+// chunkWriter sends written data as backup chunks of at most backupChunkSize bytes
+type chunkWriter func(*BackupChunk) error
+
+func (send chunkWriter) Write(p []byte) (int, error) {
+	for written := 0; written < len(p); {
+		n := len(p) - written
+		if n > backupChunkSize {
+			n = backupChunkSize
+		}
+
+		if err := send(&BackupChunk{Data: p[written : written+n]}); err != nil {
+			return written, err
+		}
+
+		written += n
+	}
+
+	return len(p), nil
 }
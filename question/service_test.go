@@ -0,0 +1,105 @@
+package question_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// bulkStream sends the requests to BulkPut and keeps its result
+type bulkStream struct {
+	grpc.ServerStream
+	requests []*question.BulkPutRequest
+	result   *question.BulkPutResult
+}
+
+func (s *bulkStream) Context() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(question.DuplicatesKey, question.DuplicatesReject))
+}
+
+func (s *bulkStream) Recv() (*question.BulkPutRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *bulkStream) SendAndClose(result *question.BulkPutResult) error {
+	s.result = result
+	return nil
+}
+
+// putManyCalls counts calls of PutMany and the questions passed to it
+type putManyCalls struct {
+	calls, questions int
+}
+
+// countingStore is a bolt storage which counts calls of PutMany
+type countingStore struct {
+	question.Store
+	counts *putManyCalls
+}
+
+func (s countingStore) PutMany(questions []question.Question, dryRun bool) ([]*question.Change, []*question.RowError, error) {
+	s.counts.calls++
+	s.counts.questions += len(questions)
+	return s.Store.PutMany(questions, dryRun)
+}
+
+func (s countingStore) CheckDuplicates(threshold float64, reject bool) question.Store {
+	return countingStore{Store: s.Store.(question.DuplicateChecker).CheckDuplicates(threshold, reject), counts: s.counts}
+}
+
+func TestBulkPutWritesBatchOnce(t *testing.T) {
+	const duplicate = "What is the capital city of France?"
+
+	for _, dryRun := range []bool{false, true} {
+		s := newBoltStorage(t)
+		if _, err := s.Put(question.Question{Text: duplicate}); err != nil {
+			t.Fatal(err)
+		}
+
+		stream := &bulkStream{}
+		for i := uint64(1); i <= 1200; i++ {
+			q := &question.Question{Text: fmt.Sprintf("%x", sha256.Sum256([]byte{byte(i), byte(i >> 8)}))}
+			if i%100 == 0 {
+				q.Text = duplicate
+			}
+
+			stream.requests = append(stream.requests, &question.BulkPutRequest{Row: i, Question: q, DryRun: dryRun})
+		}
+
+		counts := &putManyCalls{}
+		if err := question.NewRPCService(countingStore{Store: s, counts: counts}).BulkPut(stream); err != nil {
+			t.Fatal(err)
+		}
+
+		if counts.calls != 3 || counts.questions != 1200 {
+			t.Errorf("dry run %v: PutMany was called %d times with %d questions, want 3 batches of 1200 rows",
+				dryRun, counts.calls, counts.questions)
+		}
+
+		result := stream.result
+		if result.Created != 1188 || len(result.Errors) != 12 || result.Errors[0].Row != 100 {
+			t.Errorf("dry run %v: BulkPut() = %d created with errors %v, want 1188 and 12 duplicates", dryRun, result.Created, result.Errors)
+		}
+
+		want := uint64(1189)
+		if dryRun {
+			want = 1
+		}
+
+		if stored, _ := s.Count(); stored.Inactive != want {
+			t.Errorf("dry run %v: the storage has %d questions, want %d", dryRun, stored.Inactive, want)
+		}
+	}
+}
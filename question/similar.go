@@ -0,0 +1,440 @@
+package question
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	similarBandsName      = []byte("similar_bands")
+	similarSignaturesName = []byte("similar_signatures")
+)
+
+// Similar questions are found with MinHash of character shingles
+// of the normalized text and locality sensitive hashing:
+// similar_signatures maps question IDs to their MinHash signatures and
+// similar_bands has a key per band of every signature followed by the question ID,
+// so questions sharing any band are found with a prefix scan
+const (
+	minHashSize   = 64
+	minHashBands  = 16
+	minHashRows   = minHashSize / minHashBands
+	shingleLength = 3
+
+	// DefaultSimilarity is a similarity from which questions are duplicates
+	DefaultSimilarity = 0.8
+)
+
+// minHashSeeds are coefficients of the hash functions of signatures
+var minHashSeeds = func() [minHashSize][2]uint64 {
+	var seeds [minHashSize][2]uint64
+
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	for i := range seeds {
+		seeds[i] = [2]uint64{next() | 1, next()}
+	}
+
+	return seeds
+}()
+
+// Similarity is a question similar to another one
+type Similarity struct {
+	Id    uint64
+	Score float64
+}
+
+// DuplicateFinder is implemented by stores which index similarity of questions
+type DuplicateFinder interface {
+	// Similar returns other questions at least as similar to q as threshold, the most similar first
+	Similar(q *Question, threshold float64) ([]Similarity, error)
+
+	// Duplicates returns clusters of similar questions
+	Duplicates(threshold float64) ([][]uint64, error)
+}
+
+// DuplicateChecker is implemented by stores which check written questions
+// for similar ones inside the write, so concurrent writes can't both pass the check
+type DuplicateChecker interface {
+	// CheckDuplicates returns the store which looks for questions at least as similar
+	// as threshold to every written one. With reject such a write fails
+	// with *DuplicateError, otherwise similar questions are listed in Change.Similar
+	CheckDuplicates(threshold float64, reject bool) Store
+}
+
+var (
+	_ DuplicateFinder  = (*Storage)(nil)
+	_ DuplicateChecker = (*Storage)(nil)
+)
+
+// DuplicateError is returned when a written question is similar to stored ones
+// and duplicates are rejected
+type DuplicateError struct {
+	Similar []Similarity
+}
+
+func (e *DuplicateError) Error() string {
+	return describeSimilar(e.Similar)
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicate
+}
+
+// describeSimilar lists IDs of similar questions with their scores
+func describeSimilar(similar []Similarity) string {
+	described := make([]string, 0, len(similar))
+	for _, sim := range similar {
+		described = append(described, fmt.Sprintf("%d (%.0f%%)", sim.Id, sim.Score*100))
+	}
+
+	return "question is similar to " + strings.Join(described, ", ")
+}
+
+// duplicateCheck tells the storage how to check written questions for duplicates.
+// Zero threshold turns the check off
+type duplicateCheck struct {
+	threshold float64
+	reject    bool
+}
+
+// check returns questions similar to q or fails if duplicates are rejected
+func (c duplicateCheck) check(tx *bolt.Tx, q *Question) ([]Similarity, error) {
+	if c.threshold == 0 {
+		return nil, nil
+	}
+
+	similar, err := similarIn(tx, q, c.threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(similar) > 0 && c.reject {
+		return nil, &DuplicateError{Similar: similar}
+	}
+
+	return similar, nil
+}
+
+// CheckDuplicates returns the storage which checks written questions for similar ones
+func (qs *Storage) CheckDuplicates(threshold float64, reject bool) Store {
+	s := *qs
+	s.duplicates = duplicateCheck{threshold: threshold, reject: reject}
+	return &s
+}
+
+// normalize returns stemmed words of the text separated by spaces,
+// so case, punctuation and word forms don't matter
+func normalize(text string) string {
+	return strings.Join(terms(text), " ")
+}
+
+// signature returns the MinHash signature of the text or nil if it has no words
+func signature(text string) []uint32 {
+	runes := []rune(normalize(text))
+	if len(runes) == 0 {
+		return nil
+	}
+
+	sig := make([]uint32, minHashSize)
+	for i := range sig {
+		sig[i] = ^uint32(0)
+	}
+
+	for i := 0; i == 0 || i+shingleLength <= len(runes); i++ {
+		end := i + shingleLength
+		if end > len(runes) {
+			end = len(runes)
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i:end])))
+		x := h.Sum64()
+
+		for j, seed := range minHashSeeds {
+			if v := uint32((seed[0]*x + seed[1]) >> 32); v < sig[j] {
+				sig[j] = v
+			}
+		}
+	}
+
+	return sig
+}
+
+// similarity estimates Jaccard similarity of shingles by two signatures
+func similarity(a, b []uint32) float64 {
+	var same int
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+
+	return float64(same) / float64(len(a))
+}
+
+func encodeSignature(sig []uint32) []byte {
+	data := make([]byte, 4*len(sig))
+	for i, v := range sig {
+		binary.BigEndian.PutUint32(data[4*i:], v)
+	}
+
+	return data
+}
+
+func decodeSignature(data []byte) []uint32 {
+	sig := make([]uint32, len(data)/4)
+	for i := range sig {
+		sig[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+
+	return sig
+}
+
+// bandKeys returns a prefix of the band index and hash for every band of the signature
+func bandKeys(sig []uint32) [][]byte {
+	keys := make([][]byte, 0, minHashBands)
+
+	for band := 0; band < minHashBands; band++ {
+		h := fnv.New64a()
+		h.Write(encodeSignature(sig[band*minHashRows : (band+1)*minHashRows]))
+
+		key := append([]byte{byte(band)}, utils.Uinttob(h.Sum64())...)
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// similarAdd puts the signature of the question into the similarity index
+func similarAdd(tx *bolt.Tx, q *Question) error {
+	sig := signature(q.Text)
+	if sig == nil {
+		return nil
+	}
+
+	signatures, err := tx.CreateBucketIfNotExists(similarSignaturesName)
+	if err != nil {
+		return err
+	}
+
+	bands, err := tx.CreateBucketIfNotExists(similarBandsName)
+	if err != nil {
+		return err
+	}
+
+	key := utils.Uinttob(q.Id)
+	if err := signatures.Put(key, encodeSignature(sig)); err != nil {
+		return err
+	}
+
+	for _, band := range bandKeys(sig) {
+		if err := bands.Put(append(band, key...), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// similarRemove removes the question from the similarity index
+func similarRemove(tx *bolt.Tx, q *Question) error {
+	signatures := tx.Bucket(similarSignaturesName)
+	bands := tx.Bucket(similarBandsName)
+	if signatures == nil || bands == nil {
+		return nil
+	}
+
+	key := utils.Uinttob(q.Id)
+	data := signatures.Get(key)
+	if data == nil {
+		return nil
+	}
+
+	for _, band := range bandKeys(decodeSignature(data)) {
+		if err := bands.Delete(append(band, key...)); err != nil {
+			return err
+		}
+	}
+
+	return signatures.Delete(key)
+}
+
+// Similar returns other questions at least as similar to q as threshold,
+// the most similar first. A question with the same ID isn't compared
+func (qs *Storage) Similar(q *Question, threshold float64) ([]Similarity, error) {
+	var result []Similarity
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		var err error
+		result, err = similarIn(tx, q, threshold)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// similarIn looks for questions similar to q in the transaction
+func similarIn(tx *bolt.Tx, q *Question, threshold float64) ([]Similarity, error) {
+	sig := signature(q.Text)
+	signatures := tx.Bucket(similarSignaturesName)
+	bands := tx.Bucket(similarBandsName)
+	if sig == nil || signatures == nil || bands == nil {
+		return nil, nil
+	}
+
+	var result []Similarity
+	seen := make(map[uint64]bool)
+	c := bands.Cursor()
+
+	for _, band := range bandKeys(sig) {
+		for k, _ := c.Seek(band); k != nil && bytes.HasPrefix(k, band); k, _ = c.Next() {
+			id := binary.BigEndian.Uint64(k[len(band):])
+			if id == q.Id || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			data := signatures.Get(k[len(band):])
+			if data == nil {
+				continue
+			}
+
+			if score := similarity(sig, decodeSignature(data)); score >= threshold {
+				result = append(result, Similarity{Id: id, Score: score})
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+// Duplicates returns clusters of questions connected by similarity
+// of at least threshold, every cluster is sorted by ID
+func (qs *Storage) Duplicates(threshold float64) ([][]uint64, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, invalidArgument("threshold must be greater than 0 and at most 1")
+	}
+
+	parents := make(map[uint64]uint64)
+
+	var find func(id uint64) uint64
+	find = func(id uint64) uint64 {
+		parent, ok := parents[id]
+		if !ok || parent == id {
+			return id
+		}
+
+		root := find(parent)
+		parents[id] = root
+		return root
+	}
+
+	union := func(a, b uint64) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+
+		if ra > rb {
+			ra, rb = rb, ra
+		}
+		parents[ra] = ra
+		parents[rb] = ra
+	}
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		signatures := tx.Bucket(similarSignaturesName)
+		bands := tx.Bucket(similarBandsName)
+		if signatures == nil || bands == nil {
+			return nil
+		}
+
+		compared := make(map[[2]uint64]bool)
+		prefixLength := 1 + len(utils.Uinttob(0))
+
+		var group []uint64
+		var prefix []byte
+
+		compare := func() {
+			for i := 0; i < len(group); i++ {
+				for j := i + 1; j < len(group); j++ {
+					pair := [2]uint64{group[i], group[j]}
+					if compared[pair] {
+						continue
+					}
+					compared[pair] = true
+
+					a := decodeSignature(signatures.Get(utils.Uinttob(group[i])))
+					b := decodeSignature(signatures.Get(utils.Uinttob(group[j])))
+					if similarity(a, b) >= threshold {
+						union(group[i], group[j])
+					}
+				}
+			}
+		}
+
+		err := bands.ForEach(func(k, v []byte) error {
+			if !bytes.Equal(k[:prefixLength], prefix) {
+				compare()
+				prefix = append(prefix[:0], k[:prefixLength]...)
+				group = group[:0]
+			}
+
+			group = append(group, binary.BigEndian.Uint64(k[prefixLength:]))
+			return nil
+		})
+
+		compare()
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	byRoot := make(map[uint64][]uint64)
+	for id := range parents {
+		root := find(id)
+		byRoot[root] = append(byRoot[root], id)
+	}
+
+	clusters := make([][]uint64, 0, len(byRoot))
+	for _, ids := range byRoot {
+		if len(ids) < 2 {
+			continue
+		}
+
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		clusters = append(clusters, ids)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+
+	return clusters, nil
+}
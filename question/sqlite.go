@@ -0,0 +1,808 @@
+package question
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sqliteSchema creates tables of SQLiteStore. Tags are kept in a separate
+// table in their original order, so they can be queried with plain SQL
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS questions (
+	id        INTEGER PRIMARY KEY,
+	text      TEXT    NOT NULL,
+	is_good   INTEGER NOT NULL,
+	is_active INTEGER NOT NULL,
+	category  TEXT    NOT NULL DEFAULT '',
+	version   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS questions_activity ON questions (is_active, id);
+CREATE INDEX IF NOT EXISTS questions_goodness ON questions (is_active, is_good, id);
+CREATE INDEX IF NOT EXISTS questions_category ON questions (category);
+
+CREATE TABLE IF NOT EXISTS question_tags (
+	question_id INTEGER NOT NULL,
+	position    INTEGER NOT NULL,
+	tag         TEXT    NOT NULL,
+	PRIMARY KEY (question_id, position)
+);
+CREATE INDEX IF NOT EXISTS question_tags_tag ON question_tags (tag, question_id);
+
+CREATE TABLE IF NOT EXISTS sequences (
+	name  TEXT    PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS trash (
+	id         INTEGER PRIMARY KEY,
+	question   BLOB    NOT NULL,
+	deleted_at INTEGER NOT NULL,
+	deleted_by TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS trash_deleted_at ON trash (deleted_at);
+`
+
+// questionColumns are selected by every query which reads questions
+const questionColumns = "id, text, is_good, is_active, category, version"
+
+// trashColumns are selected by every query which reads the trash
+const trashColumns = "question, deleted_at, deleted_by"
+
+// sqlQuerier is implemented by both *sql.DB and *sql.Tx
+type sqlQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// SQLiteStore stores questions in a SQLite database.
+// The database must be opened with a SQLite driver, e.g. modernc.org/sqlite
+type SQLiteStore struct {
+	db    *sql.DB
+	actor Actor
+}
+
+var (
+	_ Store      = (*SQLiteStore)(nil)
+	_ ActorStore = (*SQLiteStore)(nil)
+)
+
+// NewSQLiteStore creates a new question storage in the SQLite database
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{
+		db: db,
+	}
+}
+
+// As returns the store which records deletions as made by the actor
+func (ss *SQLiteStore) As(actor Actor) Store {
+	s := *ss
+	s.actor = actor
+	return &s
+}
+
+// Init creates the tables if they are missing
+func (ss *SQLiteStore) Init() error {
+	_, err := ss.db.Exec(sqliteSchema)
+	return err
+}
+
+// inTx runs fn in a transaction which is committed if fn succeeds
+func (ss *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Put creates or updates a question
+func (ss *SQLiteStore) Put(q Question) (*Change, error) {
+	if err := validate(&q); err != nil {
+		return nil, err
+	}
+
+	var change *Change
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		var err error
+		change, err = sqlitePut(tx, q)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// PutMany creates or updates questions in one transaction, rejected questions are skipped.
+// With dryRun the transaction is rolled back, but the changes are returned
+func (ss *SQLiteStore) PutMany(questions []Question, dryRun bool) ([]*Change, []*RowError, error) {
+	var changes []*Change
+	var rowErrs []*RowError
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		var err error
+		changes, rowErrs, err = putEach(questions, func(q Question) (*Change, error) {
+			return sqlitePut(tx, q)
+		})
+
+		if err == nil && dryRun {
+			return errDryRun
+		}
+
+		return err
+	})
+
+	if err != nil && err != errDryRun {
+		return nil, nil, err
+	}
+
+	return changes, rowErrs, nil
+}
+
+// checkSQLiteID returns ErrInvalidArgument if the ID doesn't fit into SQLite INTEGER
+func checkSQLiteID(id uint64) error {
+	if id > math.MaxInt64 {
+		return invalidArgument("ID %d is greater than %d", id, uint64(math.MaxInt64))
+	}
+
+	return nil
+}
+
+// sqlitePut writes a question following the same rules as putQuestion
+func sqlitePut(tx *sql.Tx, q Question) (*Change, error) {
+	change := &Change{After: &q}
+
+	sequence, err := sqliteSequence(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Id == 0 {
+		q.Id = sequence + 1
+	} else {
+		change.Before, err = sqliteGet(tx, q.Id)
+		if err != nil && err != ErrNotFound {
+			return nil, err
+		}
+	}
+
+	if err := checkSQLiteID(q.Id); err != nil {
+		return nil, err
+	}
+
+	var stored uint64
+	if change.Before != nil {
+		stored = change.Before.Version
+	} else {
+		// A question written over a deleted one leaves the trash
+		// and continues its versions
+		trashed, err := sqliteTrashQuery(tx, "SELECT "+trashColumns+" FROM trash WHERE id = ?", q.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(trashed) > 0 {
+			stored = trashed[0].Question.Version
+		}
+	}
+
+	if err := checkVersion(q.Id, stored, q.Version); err != nil {
+		return nil, err
+	}
+	q.Version = stored + 1
+
+	if _, err := tx.Exec("DELETE FROM trash WHERE id = ?", q.Id); err != nil {
+		return nil, err
+	}
+
+	return change, sqliteWrite(tx, &q)
+}
+
+// sqliteWrite replaces a question with its tags
+// and moves the sequence forward to its ID
+func sqliteWrite(tx *sql.Tx, q *Question) error {
+	if err := checkSQLiteID(q.Id); err != nil {
+		return err
+	}
+
+	if err := sqliteSetSequence(tx, q.Id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		"INSERT OR REPLACE INTO questions ("+questionColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		q.Id, q.Text, q.IsGood, q.IsActive, q.Category, q.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM question_tags WHERE question_id = ?", q.Id); err != nil {
+		return err
+	}
+
+	for i, tag := range q.Tags {
+		_, err := tx.Exec("INSERT INTO question_tags (question_id, position, tag) VALUES (?, ?, ?)", q.Id, i, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sqliteSequence returns the last assigned ID
+func sqliteSequence(db sqlQuerier) (uint64, error) {
+	var sequence uint64
+
+	err := db.QueryRow("SELECT value FROM sequences WHERE name = 'questions'").Scan(&sequence)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
+// sqliteSetSequence moves the sequence forward, a lower value is ignored
+func sqliteSetSequence(db sqlQuerier, seq uint64) error {
+	if err := checkSQLiteID(seq); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(
+		"INSERT INTO sequences (name, value) VALUES ('questions', ?) "+
+			"ON CONFLICT (name) DO UPDATE SET value = MAX(value, excluded.value)",
+		seq,
+	)
+	return err
+}
+
+// Load writes questions as they are in one transaction,
+// the sequence is moved forward to the highest ID
+func (ss *SQLiteStore) Load(questions []Question) error {
+	for i := range questions {
+		if questions[i].Id == 0 {
+			return invalidArgument("loaded question must have an ID")
+		}
+
+		if err := checkSQLiteID(questions[i].Id); err != nil {
+			return err
+		}
+	}
+
+	return ss.inTx(func(tx *sql.Tx) error {
+		for i := range questions {
+			if _, err := sqliteTrashRemove(tx, questions[i].Id); err != nil {
+				return err
+			}
+
+			if err := sqliteWrite(tx, &questions[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Sequence returns the last assigned ID
+func (ss *SQLiteStore) Sequence() (uint64, error) {
+	return sqliteSequence(ss.db)
+}
+
+// SetSequence moves the sequence forward
+func (ss *SQLiteStore) SetSequence(seq uint64) error {
+	return sqliteSetSequence(ss.db, seq)
+}
+
+// Update changes only the fields of a stored question listed in paths
+func (ss *SQLiteStore) Update(q *Question, paths []string) (*Change, error) {
+	if err := validatePaths(paths); err != nil {
+		return nil, err
+	}
+
+	var change *Change
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		merged, err := sqliteGet(tx, q.Id)
+		if err != nil {
+			return err
+		}
+
+		if err := applyPaths(merged, q, paths); err != nil {
+			return err
+		}
+
+		change, err = sqlitePut(tx, *merged)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// Get returns a question by it's ID
+func (ss *SQLiteStore) Get(id uint64) (*Question, error) {
+	return sqliteGet(ss.db, id)
+}
+
+// sqliteGet reads a question with its tags
+func sqliteGet(db sqlQuerier, id uint64) (*Question, error) {
+	if err := checkSQLiteID(id); err != nil {
+		return nil, err
+	}
+
+	questions, err := sqliteQuery(db, "SELECT "+questionColumns+" FROM questions WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return questions[0], nil
+}
+
+// sqliteQuery reads questions selected by the query and their tags.
+// Rows are read completely before the tags are queried, so it works
+// with a single connection too
+func sqliteQuery(db sqlQuerier, query string, args ...interface{}) ([]*Question, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []*Question
+	byId := make(map[uint64]*Question)
+
+	for rows.Next() {
+		q := &Question{}
+		if err := rows.Scan(&q.Id, &q.Text, &q.IsGood, &q.IsActive, &q.Category, &q.Version); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		questions = append(questions, q)
+		byId[q.Id] = q
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return questions, nil
+	}
+
+	ids := make([]uint64, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.Id)
+	}
+
+	rows, err = db.Query(
+		"SELECT question_id, tag FROM question_tags WHERE question_id IN (SELECT value FROM json_each(?)) ORDER BY question_id, position",
+		jsonArray(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint64
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+
+		q := byId[id]
+		q.Tags = append(q.Tags, tag)
+	}
+
+	return questions, rows.Err()
+}
+
+// jsonArray encodes values for json_each, so a list of any length
+// is passed as a single query parameter
+func jsonArray(values interface{}) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// Delete moves a question into the trash
+func (ss *SQLiteStore) Delete(id uint64) (*Change, error) {
+	var change *Change
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		old, err := sqliteGet(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM questions WHERE id = ?", id); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM question_tags WHERE question_id = ?", id); err != nil {
+			return err
+		}
+
+		data, err := proto.Marshal(old)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO trash (id, question, deleted_at, deleted_by) VALUES (?, ?, ?, ?)",
+			id, data, time.Now().UnixNano(), ss.actor.Name); err != nil {
+			return err
+		}
+
+		change = &Change{Before: old}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// sqliteTrashRemove removes a question from the trash and returns it.
+// It returns nil if the question isn't there
+func sqliteTrashRemove(tx *sql.Tx, id uint64) (*TrashedQuestion, error) {
+	trashed, err := sqliteTrashQuery(tx, "SELECT "+trashColumns+" FROM trash WHERE id = ?", id)
+	if err != nil || len(trashed) == 0 {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM trash WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return trashed[0], nil
+}
+
+// sqliteTrashQuery reads questions from the trash selected by the query
+func sqliteTrashQuery(db sqlQuerier, query string, args ...interface{}) ([]*TrashedQuestion, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*TrashedQuestion
+	for rows.Next() {
+		var data []byte
+		var deletedAt int64
+		trashed := &TrashedQuestion{Question: &Question{}}
+		if err := rows.Scan(&data, &deletedAt, &trashed.DeletedBy); err != nil {
+			return nil, err
+		}
+
+		trashed.DeletedAt = timestamppb.New(time.Unix(0, deletedAt))
+		if err := proto.Unmarshal(data, trashed.Question); err != nil {
+			return nil, err
+		}
+
+		result = append(result, trashed)
+	}
+
+	return result, rows.Err()
+}
+
+// Trash returns deleted questions in order of IDs
+func (ss *SQLiteStore) Trash() ([]*TrashedQuestion, error) {
+	return sqliteTrashQuery(ss.db, "SELECT "+trashColumns+" FROM trash ORDER BY id")
+}
+
+// Restore moves a question from the trash back as its next version
+func (ss *SQLiteStore) Restore(id uint64) (*Change, error) {
+	if err := checkSQLiteID(id); err != nil {
+		return nil, err
+	}
+
+	var change *Change
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		trashed, err := sqliteTrashQuery(tx, "SELECT "+trashColumns+" FROM trash WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		if len(trashed) == 0 {
+			return ErrNotFound
+		}
+
+		q := *trashed[0].Question
+		q.Version = 0
+
+		change, err = sqlitePut(tx, q)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// Purge removes a question with req.Id or, without an ID, all questions
+// deleted before req.DeletedBefore from the trash for good
+func (ss *SQLiteStore) Purge(req *PurgeRequest) (uint64, error) {
+	before, err := purgeBefore(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var result sql.Result
+	if req.Id != 0 {
+		if err := checkSQLiteID(req.Id); err != nil {
+			return 0, err
+		}
+
+		result, err = ss.db.Exec("DELETE FROM trash WHERE id = ?", req.Id)
+	} else {
+		result, err = ss.db.Exec("DELETE FROM trash WHERE deleted_at < ?", before.UnixNano())
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if req.Id != 0 && purged == 0 {
+		return 0, ErrNotFound
+	}
+
+	return uint64(purged), nil
+}
+
+// ForEach calls fn for every question in order of IDs.
+// Questions are read in chunks, so fn may modify the store
+func (ss *SQLiteStore) ForEach(fn func(q *Question) error) error {
+	var after uint64
+
+	for {
+		chunk, err := sqliteQuery(ss.db,
+			"SELECT "+questionColumns+" FROM questions WHERE id > ? ORDER BY id LIMIT ?",
+			after, exportChunkSize,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, q := range chunk {
+			if err := fn(q); err != nil {
+				return err
+			}
+		}
+
+		if len(chunk) < exportChunkSize {
+			return nil
+		}
+
+		after = chunk[len(chunk)-1].Id
+	}
+}
+
+// Snapshot calls fn for every question in order of IDs in one transaction
+// and returns the sequence of the same snapshot
+func (ss *SQLiteStore) Snapshot(fn func(q *Question) error) (uint64, error) {
+	var seq uint64
+
+	err := ss.inTx(func(tx *sql.Tx) error {
+		var err error
+		seq, err = sqliteSequence(tx)
+		if err != nil {
+			return err
+		}
+
+		var after uint64
+		for {
+			chunk, err := sqliteQuery(tx,
+				"SELECT "+questionColumns+" FROM questions WHERE id > ? ORDER BY id LIMIT ?",
+				after, exportChunkSize,
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, q := range chunk {
+				if err := fn(q); err != nil {
+					return err
+				}
+			}
+
+			if len(chunk) < exportChunkSize {
+				return nil
+			}
+
+			after = chunk[len(chunk)-1].Id
+		}
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// Filter searches questions by filter with the same semantics as Storage.Filter.
+// Empty categories and tags never match
+func (ss *SQLiteStore) Filter(filter *Filter) (*QuestionList, error) {
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, invalidArgument("limit and offset must not be negative")
+	}
+
+	var after uint64
+	if filter.PageToken != "" {
+		key, err := decodePageToken(filter.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		after = binary.BigEndian.Uint64(key)
+	}
+
+	conditions := []string{"is_active = ?", "id > ?"}
+	args := []interface{}{filter.IsActive, after}
+
+	if len(filter.IgnoreIds) > 0 {
+		conditions = append(conditions, "id NOT IN (SELECT value FROM json_each(?))")
+		args = append(args, jsonArray(filter.IgnoreIds))
+	}
+
+	if filter.IsGood != nil {
+		conditions = append(conditions, "is_good = ?")
+		args = append(args, filter.IsGood.Value)
+	}
+
+	if len(filter.Categories) > 0 {
+		conditions = append(conditions, "category IN (SELECT value FROM json_each(?) WHERE value != '')")
+		args = append(args, jsonArray(filter.Categories))
+	}
+
+	if len(filter.Tags) > 0 {
+		if filter.AllTags {
+			tags := distinct(filter.Tags)
+			if tags == nil {
+				return &QuestionList{Questions: []*Question{}}, nil
+			}
+
+			conditions = append(conditions, "(SELECT COUNT(DISTINCT tag) FROM question_tags WHERE question_tags.question_id = questions.id AND tag IN (SELECT value FROM json_each(?))) = ?")
+			args = append(args, jsonArray(tags), len(tags))
+		} else {
+			conditions = append(conditions, "id IN (SELECT question_id FROM question_tags WHERE tag IN (SELECT value FROM json_each(?) WHERE value != ''))")
+			args = append(args, jsonArray(filter.Tags))
+		}
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM questions WHERE %s ORDER BY id LIMIT ? OFFSET ?",
+		questionColumns, strings.Join(conditions, " AND "),
+	)
+	args = append(args, filter.Limit, filter.Offset)
+
+	questions, err := sqliteQuery(ss.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &QuestionList{Questions: questions}
+	if list.Questions == nil {
+		list.Questions = []*Question{}
+	}
+
+	// Like the bolt cursor, a full page has a token if any question
+	// of the same activity follows it, even if it doesn't match the filter
+	if n := len(questions); n > 0 && int32(n) == filter.Limit {
+		last := questions[n-1].Id
+
+		var more bool
+		err := ss.db.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM questions WHERE is_active = ? AND id > ?)",
+			filter.IsActive, last,
+		).Scan(&more)
+		if err != nil {
+			return nil, err
+		}
+
+		if more {
+			list.NextPageToken = encodePageToken(utils.Uinttob(last))
+		}
+	}
+
+	return list, nil
+}
+
+// distinct returns unique values or nil if any of them is empty
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if v == "" {
+			return nil
+		}
+
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// Random returns up to req.Count uniformly random questions
+// with the requested flags, skipping ignored IDs
+func (ss *SQLiteStore) Random(req *RandomRequest) (*QuestionList, error) {
+	if req.Count < 0 {
+		return nil, invalidArgument("count must not be negative")
+	}
+
+	list := &QuestionList{}
+	if req.Count == 0 {
+		return list, nil
+	}
+
+	ignoreIds := req.IgnoreIds
+	if ignoreIds == nil {
+		ignoreIds = []uint64{}
+	}
+
+	questions, err := sqliteQuery(ss.db,
+		"SELECT "+questionColumns+" FROM questions WHERE is_active = ? AND is_good = ? "+
+			"AND id NOT IN (SELECT value FROM json_each(?)) ORDER BY random() LIMIT ?",
+		req.IsActive, req.IsGood, jsonArray(ignoreIds), req.Count,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Questions = questions
+	return list, nil
+}
+
+// Count returns numbers of active and inactive questions
+func (ss *SQLiteStore) Count() (*Counts, error) {
+	counts := &Counts{}
+
+	err := ss.db.QueryRow(
+		"SELECT COALESCE(SUM(is_active), 0), COUNT(*) - COALESCE(SUM(is_active), 0) FROM questions",
+	).Scan(&counts.Active, &counts.Inactive)
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
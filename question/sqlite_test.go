@@ -0,0 +1,49 @@
+package question_test
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/almostmoore/gbquestion/question/storetest"
+	_ "modernc.org/sqlite"
+)
+
+func newSQLiteStore(t *testing.T) question.Store {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "questions.sqlite")+"?_pragma=busy_timeout(1000)&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("Couldn't open a database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := question.NewSQLiteStore(db)
+	if err := s.Init(); err != nil {
+		t.Fatalf("Couldn't initialize the storage: %v", err)
+	}
+
+	return s
+}
+
+func TestSQLiteStore(t *testing.T) {
+	storetest.Run(t, newSQLiteStore)
+}
+
+func TestSQLiteStoreRejectsLargeIDs(t *testing.T) {
+	s := newSQLiteStore(t)
+	id := uint64(math.MaxInt64) + 1
+
+	if _, err := s.Put(question.Question{Id: id, Text: "Question"}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Put returned %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := s.Get(id); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Get returned %v, want ErrInvalidArgument", err)
+	}
+
+	if err := s.Load([]question.Question{{Id: id, Text: "Question"}}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Load returned %v, want ErrInvalidArgument", err)
+	}
+}
@@ -1,18 +1,32 @@
 package question
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/almostmoore/gbquestion/utils"
 	"github.com/boltdb/bolt"
 	"github.com/golang/protobuf/proto"
+	context "golang.org/x/net/context"
 )
 
 var questionsBucketName = []byte("questions")
 
+// errDryRun rolls back a transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// exportChunkSize is a number of questions read in one transaction by ForEach
+const exportChunkSize = 1000
+
 // Storage stores questions
 type Storage struct {
-	db *bolt.DB
+	db           *bolt.DB
+	actor        Actor
+	duplicates   duplicateCheck
+	keepVersions int
 }
 
 // NewStorage creates a new question storage
@@ -22,33 +36,213 @@ func NewStorage(DB *bolt.DB) *Storage {
 	}
 }
 
-// Put creates or updates a question into db
-func (qs *Storage) Put(q Question) (uint64, error) {
-	id := q.Id
+// Init creates buckets and builds the indexes if they are missing,
+// e.g. for a database created before the indexes were introduced
+func (qs *Storage) Init() error {
+	return qs.InitContext(context.Background())
+}
 
-	err := qs.db.Batch(func(tx *bolt.Tx) error {
+// InitContext is Init which stops building the indexes when ctx is done.
+// Nothing is written then, the indexes are built on the next start
+func (qs *Storage) InitContext(ctx context.Context) error {
+	return qs.db.Update(func(tx *bolt.Tx) error {
 		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
 		if err != nil {
 			return err
 		}
 
-		if id == 0 {
-			id, err = b.NextSequence()
-			if err != nil {
+		backfill, err := createIndexes(tx)
+		if err != nil || len(backfill) == 0 {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			if err := ctx.Err(); err != nil {
 				return err
 			}
+
+			q := &Question{}
+			if err := proto.Unmarshal(v, q); err != nil {
+				return err
+			}
+
+			for _, add := range backfill {
+				if err := add(tx, q); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// Change describes a question before and after a modification.
+// Before is nil for a created question and After is nil for a deleted one
+type Change struct {
+	Before *Question
+	After  *Question
+
+	// Similar lists stored questions similar to the written one
+	// if the store checks duplicates
+	Similar []Similarity
+}
+
+// Put creates or updates a question into db
+func (qs *Storage) Put(q Question) (*Change, error) {
+	if err := validate(&q); err != nil {
+		return nil, err
+	}
+
+	var change *Change
+
+	err := qs.db.Batch(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
+		if err != nil {
+			return err
 		}
 
-		q.Id = id
-		data, err := proto.Marshal(&q)
+		change, err = qs.putQuestion(tx, b, q)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// PutMany creates or updates questions in one transaction, rejected questions are skipped.
+// With dryRun the transaction is rolled back, but the changes are returned
+func (qs *Storage) PutMany(questions []Question, dryRun bool) ([]*Change, []*RowError, error) {
+	var changes []*Change
+	var rowErrs []*RowError
+
+	err := qs.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
 		if err != nil {
 			return err
 		}
 
-		return b.Put(utils.Uinttob(id), data)
+		changes, rowErrs, err = putEach(questions, func(q Question) (*Change, error) {
+			return qs.putQuestion(tx, b, q)
+		})
+
+		if err == nil && dryRun {
+			return errDryRun
+		}
+
+		return err
 	})
 
-	return id, err
+	if err != nil && err != errDryRun {
+		return nil, nil, err
+	}
+
+	return changes, rowErrs, nil
+}
+
+// putQuestion writes a question, updates the indexes, saves the written
+// version into the history and the change into the audit log as made by the actor.
+// A question without ID gets the next one from the bucket sequence,
+// an explicit ID moves the sequence forward so it won't be reused.
+// A non-zero version must match the stored one, the written question
+// gets the next version. A new text is checked for duplicates if the storage checks them.
+// The question is checked before anything is written, so a rejected one leaves the transaction as it was
+func (qs *Storage) putQuestion(tx *bolt.Tx, b *bolt.Bucket, q Question) (*Change, error) {
+	var err error
+	change := &Change{After: &q}
+
+	var stored uint64
+	if q.Id != 0 {
+		if change.Before, err = storedQuestion(b, q.Id); err != nil {
+			return nil, err
+		}
+
+		if change.Before != nil {
+			stored = change.Before.Version
+		} else {
+			// A question written over a deleted one leaves the trash
+			// and continues its versions
+			trashed, err := trashGet(tx, q.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			if trashed != nil {
+				stored = trashed.Question.Version
+			}
+		}
+	}
+
+	if err := checkVersion(q.Id, stored, q.Version); err != nil {
+		return nil, err
+	}
+
+	if change.Before == nil || change.Before.Text != q.Text {
+		change.Similar, err = qs.duplicates.check(tx, &q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case q.Id == 0:
+		q.Id, err = b.NextSequence()
+	case change.Before != nil:
+		err = indexRemove(tx, change.Before)
+	default:
+		_, err = trashRemove(tx, q.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Id > b.Sequence() {
+		if err := b.SetSequence(q.Id); err != nil {
+			return nil, err
+		}
+	}
+	q.Version = stored + 1
+
+	data, err := proto.Marshal(&q)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.Put(utils.Uinttob(q.Id), data); err != nil {
+		return nil, err
+	}
+
+	if err := historyAdd(tx, &q, qs.actor, qs.keepVersions); err != nil {
+		return nil, err
+	}
+
+	if err := auditAdd(tx, qs.actor, change.Before, &q); err != nil {
+		return nil, err
+	}
+
+	return change, indexAdd(tx, &q)
+}
+
+// checkVersion returns ErrConflict if an expected version
+// is set and doesn't match the stored one
+func checkVersion(id, stored, expected uint64) error {
+	if expected != 0 && expected != stored {
+		return fmt.Errorf("%w: question %d has version %d, not %d", ErrConflict, id, stored, expected)
+	}
+
+	return nil
+}
+
+// validate checks that a question can be stored
+func validate(q *Question) error {
+	if strings.TrimSpace(q.Text) == "" {
+		return invalidArgument("text of the question must not be empty")
+	}
+
+	return nil
 }
 
 // Get returns a question by it's ID
@@ -57,25 +251,270 @@ func (qs *Storage) Get(id uint64) (*Question, error) {
 
 	err := qs.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return ErrNotFound
+		}
 
 		data := b.Get(utils.Uinttob(id))
+		if data == nil {
+			return ErrNotFound
+		}
+
 		return proto.Unmarshal(data, q)
 	})
 
-	return q, err
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
 }
 
-// Delete func removes question by id
-func (qs *Storage) Delete(id uint64) error {
-	return qs.db.Batch(func(tx *bolt.Tx) error {
+// Delete func moves question by id into the trash
+func (qs *Storage) Delete(id uint64) (*Change, error) {
+	var change *Change
+
+	err := qs.db.Batch(func(tx *bolt.Tx) error {
 		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return ErrNotFound
+		}
+
+		old, err := removeFromIndexes(tx, b, id)
+		if err != nil {
+			return err
+		}
+
+		if old == nil {
+			return ErrNotFound
+		}
+
+		change = &Change{Before: old}
+		if err := trashAdd(tx, old, qs.actor); err != nil {
+			return err
+		}
+
+		if err := auditAdd(tx, qs.actor, old, nil); err != nil {
+			return err
+		}
+
 		return b.Delete(utils.Uinttob(id))
 	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
 }
 
-// Filter func searches questions by filter
-func (qs *Storage) Filter(filter *Filter) ([]*Question, error) {
-	questions := make([]*Question, 0, filter.Limit)
+// Snapshot calls fn for every question in order of IDs in one read transaction
+// and returns the sequence of the same snapshot. Writers aren't blocked,
+// but the transaction is open until fn returns for the last question
+func (qs *Storage) Snapshot(fn func(q *Question) error) (uint64, error) {
+	var seq uint64
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return nil
+		}
+
+		seq = b.Sequence()
+		return b.ForEach(func(k, v []byte) error {
+			q := &Question{}
+			if err := proto.Unmarshal(v, q); err != nil {
+				return err
+			}
+
+			return fn(q)
+		})
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// ForEach calls fn for every question in order of IDs.
+// Questions are read in chunks, so a slow fn doesn't hold a long transaction
+func (qs *Storage) ForEach(fn func(q *Question) error) error {
+	var after []byte
+
+	for {
+		var chunk []*Question
+
+		err := qs.db.View(func(tx *bolt.Tx) error {
+			b := tx.Bucket(questionsBucketName)
+			if b == nil {
+				return nil
+			}
+
+			c := b.Cursor()
+			k, v := c.First()
+			if after != nil {
+				k, v = c.Seek(after)
+				if bytes.Equal(k, after) {
+					k, v = c.Next()
+				}
+			}
+
+			for ; k != nil && len(chunk) < exportChunkSize; k, v = c.Next() {
+				q := &Question{}
+				if err := proto.Unmarshal(v, q); err != nil {
+					return err
+				}
+
+				chunk = append(chunk, q)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, q := range chunk {
+			if err := fn(q); err != nil {
+				return err
+			}
+		}
+
+		if len(chunk) < exportChunkSize {
+			return nil
+		}
+
+		after = utils.Uinttob(chunk[len(chunk)-1].Id)
+	}
+}
+
+// Load writes questions as they are in one transaction,
+// the sequence is moved forward to the highest ID
+func (qs *Storage) Load(questions []Question) error {
+	for i := range questions {
+		if questions[i].Id == 0 {
+			return invalidArgument("loaded question must have an ID")
+		}
+	}
+
+	return qs.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
+		if err != nil {
+			return err
+		}
+
+		for i := range questions {
+			q := &questions[i]
+			if _, err := removeFromIndexes(tx, b, q.Id); err != nil {
+				return err
+			}
+
+			if _, err := trashRemove(tx, q.Id); err != nil {
+				return err
+			}
+
+			data, err := proto.Marshal(q)
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put(utils.Uinttob(q.Id), data); err != nil {
+				return err
+			}
+
+			if err := indexAdd(tx, q); err != nil {
+				return err
+			}
+
+			if q.Id > b.Sequence() {
+				if err := b.SetSequence(q.Id); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Sequence returns the sequence of the questions bucket
+func (qs *Storage) Sequence() (uint64, error) {
+	var seq uint64
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(questionsBucketName); b != nil {
+			seq = b.Sequence()
+		}
+		return nil
+	})
+
+	return seq, err
+}
+
+// SetSequence moves the sequence of the questions bucket forward
+func (qs *Storage) SetSequence(seq uint64) error {
+	return qs.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
+		if err != nil || seq <= b.Sequence() {
+			return err
+		}
+
+		return b.SetSequence(seq)
+	})
+}
+
+// removeFromIndexes removes a stored question from the indexes
+// before it is overwritten or deleted and returns it.
+// It returns nil if there is no such question
+func removeFromIndexes(tx *bolt.Tx, b *bolt.Bucket, id uint64) (*Question, error) {
+	old, err := storedQuestion(b, id)
+	if err != nil || old == nil {
+		return nil, err
+	}
+
+	return old, indexRemove(tx, old)
+}
+
+// storedQuestion reads a question from the bucket or returns nil if it isn't there
+func storedQuestion(b *bolt.Bucket, id uint64) (*Question, error) {
+	data := b.Get(utils.Uinttob(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	q := &Question{}
+	if err := proto.Unmarshal(data, q); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Filter func searches questions by filter.
+// It walks the activity index instead of the whole questions bucket
+// and starts right after the key encoded into the page token.
+// Categories match if a question has any of them, tags match
+// if a question has any of them or all of them when AllTags is set
+func (qs *Storage) Filter(filter *Filter) (*QuestionList, error) {
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, invalidArgument("limit and offset must not be negative")
+	}
+
+	list := &QuestionList{
+		Questions: make([]*Question, 0, filter.Limit),
+	}
+
+	var after []byte
+	if filter.PageToken != "" {
+		var err error
+		after, err = decodePageToken(filter.PageToken)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	ignoreIds := make(map[uint64]bool, len(filter.IgnoreIds))
 	for i := 0; i < len(filter.IgnoreIds); i++ {
@@ -84,22 +523,52 @@ func (qs *Storage) Filter(filter *Filter) ([]*Question, error) {
 
 	err := qs.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(questionsBucketName)
-		c := b.Cursor()
+		idx := tx.Bucket(activityIndexName(filter.IsActive))
+		if b == nil || idx == nil {
+			return nil
+		}
+
+		var goodness *bolt.Bucket
+		if filter.IsGood != nil {
+			if goodness = tx.Bucket(goodnessIndexName(filter.IsGood.Value)); goodness == nil {
+				return nil
+			}
+		}
+
+		categories := valueBuckets(tx, categoryIndexName, filter.Categories)
+		tags := valueBuckets(tx, tagIndexName, filter.Tags)
+		matchTags := containsAny
+		if filter.AllTags {
+			matchTags = containsAll
+		}
+
+		c := idx.Cursor()
+		k, _ := c.First()
+		if after != nil {
+			k, _ = c.Seek(after)
+			if bytes.Equal(k, after) {
+				k, _ = c.Next()
+			}
+		}
+
 		var offset int32
+		var last []byte
 
-		for k, v := c.First(); k != nil && int32(len(questions)) < filter.Limit; k, v = c.Next() {
+		for ; k != nil && int32(len(list.Questions)) < filter.Limit; k, _ = c.Next() {
 			uintKey := binary.BigEndian.Uint64(k)
 			if ignoreIds[uintKey] {
 				continue
 			}
 
-			q := &Question{}
-			err := proto.Unmarshal(v, q)
-			if err != nil {
-				return err
+			if goodness != nil && goodness.Get(k) == nil {
+				continue
 			}
 
-			if q.IsActive != filter.IsActive {
+			if len(categories) > 0 && !containsAny(categories, k) {
+				continue
+			}
+
+			if len(tags) > 0 && !matchTags(tags, k) {
 				continue
 			}
 
@@ -108,11 +577,26 @@ func (qs *Storage) Filter(filter *Filter) ([]*Question, error) {
 				continue
 			}
 
-			questions = append(questions, q)
+			q := &Question{}
+			err := proto.Unmarshal(b.Get(k), q)
+			if err != nil {
+				return err
+			}
+
+			list.Questions = append(list.Questions, q)
+			last = k
+		}
+
+		if k != nil && last != nil {
+			list.NextPageToken = encodePageToken(last)
 		}
 
 		return nil
 	})
 
-	return questions, err
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
@@ -0,0 +1,140 @@
+package question_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/almostmoore/gbquestion/question"
+	"github.com/almostmoore/gbquestion/question/storetest"
+	"github.com/boltdb/bolt"
+)
+
+func newBoltStorage(t *testing.T) *question.Storage {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "questions.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Couldn't open a database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := question.NewStorage(db)
+	if err := s.Init(); err != nil {
+		t.Fatalf("Couldn't initialize the storage: %v", err)
+	}
+
+	return s
+}
+
+func TestStorage(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) question.Store {
+		return newBoltStorage(t)
+	})
+}
+
+func TestStorageRejectsDuplicates(t *testing.T) {
+	s := newBoltStorage(t)
+	checked := s.CheckDuplicates(question.DefaultSimilarity, true)
+
+	const text = "What is the capital city of France?"
+
+	var wg sync.WaitGroup
+	var created int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := checked.Put(question.Question{Text: text}); err == nil {
+				atomic.AddInt32(&created, 1)
+			} else if !errors.Is(err, question.ErrDuplicate) {
+				t.Errorf("Put error = %v, want ErrDuplicate", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Fatalf("%d of concurrent duplicates were created, want 1", created)
+	}
+
+	other, err := checked.Put(question.Question{Text: "Who wrote War and Peace?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := checked.Update(&question.Question{Id: other.After.Id, Text: text + "!"}, []string{"text"}); !errors.Is(err, question.ErrDuplicate) {
+		t.Errorf("Update error = %v, want ErrDuplicate", err)
+	}
+
+	if _, err := checked.Update(&question.Question{Id: other.After.Id, IsGood: true}, []string{"isGood"}); err != nil {
+		t.Errorf("Update keeping the text error = %v, want nil", err)
+	}
+
+	batch := []question.Question{{Text: "Who painted the Mona Lisa?"}, {Text: "Who painted the Mona Lisa"}}
+	changes, rowErrs, err := checked.PutMany(batch, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rowErrs) != 1 || rowErrs[0].Index != 1 || !errors.Is(rowErrs[0], question.ErrDuplicate) || changes[0] == nil {
+		t.Errorf("PutMany row errors = %v, want a duplicate of question 0 in question 1", rowErrs)
+	}
+
+	change, err := s.CheckDuplicates(question.DefaultSimilarity, false).Put(question.Question{Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(change.Similar) != 1 || change.Similar[0].Score < question.DefaultSimilarity {
+		t.Errorf("Similar = %v, want the first question", change.Similar)
+	}
+}
+
+func TestStorageInitContext(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "questions.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("Couldn't open a database: %v", err)
+	}
+	defer db.Close()
+
+	s := question.NewStorage(db)
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"one", "two"} {
+		if _, err := s.Put(question.Question{Text: text, IsActive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The database looks as if it was written before the index was introduced
+	if err := db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket([]byte("index_active")) }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.InitContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("InitContext() with a cancelled context error = %v, want context.Canceled", err)
+	}
+
+	db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("index_active")) != nil {
+			t.Errorf("a cancelled InitContext() created the index")
+		}
+		return nil
+	})
+
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.Filter(&question.Filter{IsActive: true, Limit: 10})
+	if err != nil || len(list.Questions) != 2 {
+		t.Errorf("Filter() after Init = %v, %v, want both questions", list, err)
+	}
+}
@@ -0,0 +1,99 @@
+package question
+
+import (
+	context "golang.org/x/net/context"
+)
+
+// Store is a storage of questions. Storage keeps them in bolt
+// and MemoryStore in memory, both behave the same way
+type Store interface {
+	// Init prepares the store for use, e.g. builds missing indexes
+	Init() error
+
+	// Put creates or updates a question
+	Put(q Question) (*Change, error)
+
+	// PutMany creates or updates questions at once. A question which is invalid or rejected,
+	// e.g. by a version conflict, is skipped with *RowError and its change is nil,
+	// the others are written. Any other error fails the call and none of them is written
+	PutMany(questions []Question, dryRun bool) ([]*Change, []*RowError, error)
+
+	// Update changes only the fields of a stored question listed in paths
+	Update(q *Question, paths []string) (*Change, error)
+
+	// Get returns a question by it's ID
+	Get(id uint64) (*Question, error)
+
+	// Delete removes a question by ID
+	Delete(id uint64) (*Change, error)
+
+	// Filter searches questions by filter
+	Filter(filter *Filter) (*QuestionList, error)
+
+	// Random returns uniformly random questions
+	Random(req *RandomRequest) (*QuestionList, error)
+
+	// ForEach calls fn for every question in order of IDs
+	ForEach(fn func(q *Question) error) error
+
+	// Count returns numbers of active and inactive questions
+	Count() (*Counts, error)
+
+	// Load writes questions as they are, keeping their IDs and versions.
+	// It is used to copy questions between stores
+	Load(questions []Question) error
+
+	// Sequence returns the last assigned ID
+	Sequence() (uint64, error)
+
+	// SetSequence moves the sequence forward, a lower value is ignored
+	SetSequence(seq uint64) error
+}
+
+// Snapshotter is implemented by stores which can read all questions
+// from one consistent snapshot
+type Snapshotter interface {
+	// Snapshot calls fn for every question in order of IDs
+	// and returns the sequence of the same snapshot
+	Snapshot(fn func(q *Question) error) (uint64, error)
+}
+
+// CancelableIniter is implemented by stores whose Init may take a while.
+// InitContext stops when ctx is done and leaves the store as it was
+type CancelableIniter interface {
+	InitContext(ctx context.Context) error
+}
+
+var (
+	_ Store            = (*Storage)(nil)
+	_ CancelableIniter = (*Storage)(nil)
+	_ Snapshotter      = (*Storage)(nil)
+	_ Snapshotter      = (*MemoryStore)(nil)
+	_ Snapshotter      = (*SQLiteStore)(nil)
+)
+
+// putEach writes questions one by one with put. Invalid questions and the ones
+// put rejects are skipped, any other error stops it. put must check everything
+// which may reject a question before writing it
+func putEach(questions []Question, put func(q Question) (*Change, error)) ([]*Change, []*RowError, error) {
+	changes := make([]*Change, len(questions))
+	var rowErrs []*RowError
+
+	for i, q := range questions {
+		err := validate(&q)
+		if err == nil {
+			changes[i], err = put(q)
+		}
+
+		if err != nil {
+			if !rejected(err) {
+				return nil, nil, err
+			}
+
+			changes[i] = nil
+			rowErrs = append(rowErrs, &RowError{Index: i, Err: err})
+		}
+	}
+
+	return changes, rowErrs, nil
+}
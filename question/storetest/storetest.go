@@ -0,0 +1,739 @@
+// Package storetest checks that an implementation of question.Store
+// behaves the same way as the bolt storage
+package storetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Run runs the conformance suite. newStore must return a new empty
+// and initialized store for every call
+func Run(t *testing.T, newStore func(t *testing.T) question.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s question.Store)
+	}{
+		{"PutGet", testPutGet},
+		{"Validate", testValidate},
+		{"Sequence", testSequence},
+		{"Version", testVersion},
+		{"Delete", testDelete},
+		{"Trash", testTrash},
+		{"TrashActor", testTrashActor},
+		{"Purge", testPurge},
+		{"PutMany", testPutMany},
+		{"Update", testUpdate},
+		{"Filter", testFilter},
+		{"FilterPages", testFilterPages},
+		{"FilterValues", testFilterValues},
+		{"Random", testRandom},
+		{"ForEach", testForEach},
+		{"Snapshot", testSnapshot},
+		{"Count", testCount},
+		{"Load", testLoad},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// put stores a question and fails the test on error
+func put(t *testing.T, s question.Store, q question.Question) *question.Question {
+	t.Helper()
+
+	change, err := s.Put(q)
+	if err != nil {
+		t.Fatalf("Put(%q): %v", q.Text, err)
+	}
+
+	return change.After
+}
+
+// ids returns IDs of the questions
+func ids(questions []*question.Question) []uint64 {
+	result := make([]uint64, 0, len(questions))
+	for _, q := range questions {
+		result = append(result, q.Id)
+	}
+
+	return result
+}
+
+func equalIds(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testPutGet(t *testing.T, s question.Store) {
+	change, err := s.Put(question.Question{Text: "first", IsActive: true, Category: "music", Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change.Before != nil {
+		t.Errorf("Before = %v, want nil for a new question", change.Before)
+	}
+
+	q := change.After
+	if q.Id != 1 || q.Version != 1 {
+		t.Errorf("got id %d version %d, want 1 and 1", q.Id, q.Version)
+	}
+
+	got, err := s.Get(q.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Text != "first" || !got.IsActive || got.Category != "music" || len(got.Tags) != 2 {
+		t.Errorf("Get = %v, want the stored question", got)
+	}
+
+	got.Text = "changed"
+	if again, _ := s.Get(q.Id); again.Text != "first" {
+		t.Errorf("modifying a returned question changed the stored one")
+	}
+
+	if _, err := s.Get(42); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func testValidate(t *testing.T, s question.Store) {
+	if _, err := s.Put(question.Question{Text: "  "}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Put(blank) error = %v, want ErrInvalidArgument", err)
+	}
+
+	changes, rowErrs, err := s.PutMany([]question.Question{{}, {Text: " "}}, false)
+	if err != nil || len(changes) != 2 || changes[0] != nil || changes[1] != nil {
+		t.Fatalf("PutMany(blank) = %v, %v, want both skipped", changes, err)
+	}
+
+	for i, rowErr := range rowErrs {
+		if rowErr.Index != i || !errors.Is(rowErr, question.ErrInvalidArgument) {
+			t.Errorf("PutMany(blank) row error %d = %v, want ErrInvalidArgument of question %d", i, rowErr, i)
+		}
+	}
+
+	if counts, _ := s.Count(); counts.Active+counts.Inactive != 0 {
+		t.Errorf("invalid questions were stored")
+	}
+}
+
+func testSequence(t *testing.T, s question.Store) {
+	put(t, s, question.Question{Text: "one"})
+	put(t, s, question.Question{Id: 10, Text: "ten"})
+
+	if q := put(t, s, question.Question{Text: "eleven"}); q.Id != 11 {
+		t.Errorf("id after an explicit one = %d, want 11", q.Id)
+	}
+
+	put(t, s, question.Question{Id: 5, Text: "five"})
+	if q := put(t, s, question.Question{Text: "twelve"}); q.Id != 12 {
+		t.Errorf("id after a lower explicit one = %d, want 12", q.Id)
+	}
+}
+
+func testVersion(t *testing.T, s question.Store) {
+	q := put(t, s, question.Question{Text: "v1"})
+
+	q.Text = "v2"
+	change, err := s.Put(*q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change.Before == nil || change.Before.Text != "v1" || change.After.Version != 2 {
+		t.Errorf("change = %v, want v1 replaced by version 2", change)
+	}
+
+	q.Text = "stale"
+	if _, err := s.Put(*q); !errors.Is(err, question.ErrConflict) {
+		t.Errorf("Put(stale version) error = %v, want ErrConflict", err)
+	}
+
+	q.Version = 0
+	if change, err := s.Put(*q); err != nil || change.After.Version != 3 {
+		t.Errorf("Put(no version) = %v, %v, want version 3", change, err)
+	}
+
+	if _, err := s.Put(question.Question{Text: "new", Version: 1}); !errors.Is(err, question.ErrConflict) {
+		t.Errorf("Put(new with version) error = %v, want ErrConflict", err)
+	}
+}
+
+func testDelete(t *testing.T, s question.Store) {
+	q := put(t, s, question.Question{Text: "gone", IsActive: true})
+
+	change, err := s.Delete(q.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change.Before == nil || change.Before.Id != q.Id || change.After != nil {
+		t.Errorf("change = %v, want the deleted question", change)
+	}
+
+	if _, err := s.Get(q.Id); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Get(deleted) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.Delete(q.Id); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Delete(deleted) error = %v, want ErrNotFound", err)
+	}
+
+	list, _ := s.Filter(&question.Filter{IsActive: true, Limit: 10})
+	if len(list.Questions) != 0 {
+		t.Errorf("Filter returned a deleted question")
+	}
+}
+
+func testTrash(t *testing.T, s question.Store) {
+	trasher, ok := s.(question.Trasher)
+	if !ok {
+		t.Skip("the store doesn't keep deleted questions")
+	}
+
+	q := put(t, s, question.Question{Text: "gone", IsActive: true, Tags: []string{"a"}})
+	q.Text = "gone v2"
+	q = put(t, s, *q)
+
+	if _, err := s.Delete(q.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	trash, err := trasher.Trash()
+	if err != nil || len(trash) != 1 || trash[0].Question.Text != "gone v2" || trash[0].DeletedAt == nil {
+		t.Fatalf("Trash() = %v, %v, want the deleted question with the time of deletion", trash, err)
+	}
+
+	change, err := trasher.Restore(q.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change.After.Version != 3 || change.After.Text != "gone v2" || len(change.After.Tags) != 1 {
+		t.Errorf("Restore() = %v, want version 3 of the deleted question", change.After)
+	}
+
+	if got, err := s.Get(q.Id); err != nil || got.Version != 3 {
+		t.Errorf("Get(restored) = %v, %v, want version 3", got, err)
+	}
+
+	if _, err := trasher.Restore(q.Id); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Restore(restored) error = %v, want ErrNotFound", err)
+	}
+
+	// A question written over a deleted one leaves the trash and continues its versions
+	if _, err := s.Delete(q.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Put(question.Question{Id: q.Id, Text: "again", Version: 1}); !errors.Is(err, question.ErrConflict) {
+		t.Errorf("Put(over deleted with a stale version) error = %v, want ErrConflict", err)
+	}
+
+	if got := put(t, s, question.Question{Id: q.Id, Text: "again"}); got.Version != 4 {
+		t.Errorf("Put(over deleted) version = %d, want 4", got.Version)
+	}
+
+	if trash, err := trasher.Trash(); err != nil || len(trash) != 0 {
+		t.Errorf("Trash() after Put = %v, %v, want none", trash, err)
+	}
+
+	// A dry run keeps the question in the trash
+	if _, err := s.Delete(q.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.PutMany([]question.Question{{Id: q.Id, Text: "dry"}}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if trash, err := trasher.Trash(); err != nil || len(trash) != 1 {
+		t.Errorf("Trash() after a dry run = %v, %v, want the deleted question", trash, err)
+	}
+}
+
+func testTrashActor(t *testing.T, s question.Store) {
+	trasher, ok := s.(question.Trasher)
+	if !ok {
+		t.Skip("the store doesn't keep deleted questions")
+	}
+
+	as, ok := s.(question.ActorStore)
+	if !ok {
+		t.Skip("the store doesn't record actors")
+	}
+
+	first := put(t, s, question.Question{Text: "first"})
+	second := put(t, s, question.Question{Text: "second"})
+
+	if _, err := as.As(question.Actor{Name: "alice", Method: "Delete"}).Delete(first.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Delete(second.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	trash, err := trasher.Trash()
+	if err != nil || len(trash) != 2 {
+		t.Fatalf("Trash() = %v, %v, want both deleted questions", trash, err)
+	}
+
+	if trash[0].DeletedBy != "alice" || trash[1].DeletedBy != "" {
+		t.Errorf("Trash() deleted by %q and %q, want alice and nobody", trash[0].DeletedBy, trash[1].DeletedBy)
+	}
+}
+
+func testPurge(t *testing.T, s question.Store) {
+	trasher, ok := s.(question.Trasher)
+	if !ok {
+		t.Skip("the store doesn't keep deleted questions")
+	}
+
+	if _, err := trasher.Purge(&question.PurgeRequest{}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Purge() without an ID and time error = %v, want ErrInvalidArgument", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		q := put(t, s, question.Question{Text: "q"})
+		if _, err := s.Delete(q.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := trasher.Purge(&question.PurgeRequest{Id: 2}); err != nil || n != 1 {
+		t.Errorf("Purge(2) = %d, %v, want 1", n, err)
+	}
+
+	if _, err := trasher.Purge(&question.PurgeRequest{Id: 2}); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Purge(purged) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := trasher.Restore(2); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Restore(purged) error = %v, want ErrNotFound", err)
+	}
+
+	old := timestamppb.New(time.Now().Add(-time.Hour))
+	if n, err := trasher.Purge(&question.PurgeRequest{DeletedBefore: old}); err != nil || n != 0 {
+		t.Errorf("Purge(deleted an hour ago) = %d, %v, want 0", n, err)
+	}
+
+	now := timestamppb.New(time.Now().Add(time.Second))
+	if n, err := trasher.Purge(&question.PurgeRequest{DeletedBefore: now}); err != nil || n != 2 {
+		t.Errorf("Purge(deleted before now) = %d, %v, want 2", n, err)
+	}
+
+	if trash, err := trasher.Trash(); err != nil || len(trash) != 0 {
+		t.Errorf("Trash() after Purge = %v, %v, want none", trash, err)
+	}
+}
+
+func testPutMany(t *testing.T, s question.Store) {
+	existing := put(t, s, question.Question{Text: "existing"})
+
+	batch := []question.Question{{Text: "a"}, {Id: existing.Id, Text: "updated"}, {Text: "b"}}
+	changes, rowErrs, err := s.PutMany(batch, true)
+	if err != nil || len(rowErrs) != 0 {
+		t.Fatal(err, rowErrs)
+	}
+
+	if len(changes) != 3 || changes[0].After.Id != 2 || changes[1].Before == nil || changes[2].After.Id != 3 {
+		t.Errorf("dry run changes = %v", changes)
+	}
+
+	if counts, _ := s.Count(); counts.Inactive != 1 {
+		t.Errorf("dry run stored questions")
+	}
+
+	if q := put(t, s, question.Question{Text: "next"}); q.Id != 2 {
+		t.Errorf("id after a dry run = %d, want 2", q.Id)
+	}
+
+	// A rejected question is skipped and the rest of the batch is written
+	conflict := []question.Question{{Text: "c"}, {Id: existing.Id, Text: "x", Version: 7}, {Text: "d"}}
+	changes, rowErrs, err = s.PutMany(conflict, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rowErrs) != 1 || rowErrs[0].Index != 1 || !errors.Is(rowErrs[0], question.ErrConflict) {
+		t.Errorf("PutMany(conflict) row errors = %v, want ErrConflict of question 1", rowErrs)
+	}
+
+	if len(changes) != 3 || changes[0] == nil || changes[1] != nil || changes[2] == nil {
+		t.Errorf("PutMany(conflict) changes = %v, want all but question 1", changes)
+	}
+
+	if counts, _ := s.Count(); counts.Inactive != 4 {
+		t.Errorf("PutMany(conflict) stored %d questions, want 4", counts.Inactive)
+	}
+
+	if got, _ := s.Get(existing.Id); got.Text != "existing" || got.Version != 1 {
+		t.Errorf("Get after a rejected write = %v, want the question untouched", got)
+	}
+
+	list, err := s.Filter(&question.Filter{Limit: 10})
+	if err != nil || len(list.Questions) != 4 || list.Questions[0].Id != existing.Id {
+		t.Errorf("Filter after a rejected write = %v, %v, want the question still listed", list, err)
+	}
+
+	changes, _, err = s.PutMany(batch, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := s.Get(existing.Id); got.Text != "updated" || got.Version != 2 {
+		t.Errorf("Get after PutMany = %v", got)
+	}
+
+	if counts, _ := s.Count(); counts.Inactive != 6 {
+		t.Errorf("PutMany stored %d questions, want 6", counts.Inactive)
+	}
+}
+
+func testUpdate(t *testing.T, s question.Store) {
+	q := put(t, s, question.Question{Text: "text", Category: "music", IsGood: true})
+
+	change, err := s.Update(&question.Question{Id: q.Id, IsActive: true, Text: "ignored"}, []string{"isActive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := change.After
+	if !got.IsActive || got.Text != "text" || got.Category != "music" || !got.IsGood || got.Version != 2 {
+		t.Errorf("Update = %v, want only isActive changed", got)
+	}
+
+	if _, err := s.Update(&question.Question{Id: q.Id}, nil); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Update(no paths) error = %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := s.Update(&question.Question{Id: q.Id}, []string{"id"}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Update(id) error = %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := s.Update(&question.Question{Id: q.Id}, []string{"text"}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Update(empty text) error = %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := s.Update(&question.Question{Id: q.Id, Version: 1}, []string{"isGood"}); !errors.Is(err, question.ErrConflict) {
+		t.Errorf("Update(stale version) error = %v, want ErrConflict", err)
+	}
+
+	if _, err := s.Update(&question.Question{Id: 42}, []string{"isGood"}); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("Update(missing) error = %v, want ErrNotFound", err)
+	}
+
+	list, _ := s.Filter(&question.Filter{IsActive: true, Limit: 10})
+	if !equalIds(ids(list.Questions), []uint64{q.Id}) {
+		t.Errorf("updated question isn't listed as active")
+	}
+}
+
+func testFilter(t *testing.T, s question.Store) {
+	for i := 1; i <= 6; i++ {
+		put(t, s, question.Question{Text: "q", IsActive: i%2 == 1})
+	}
+
+	tests := []struct {
+		filter *question.Filter
+		want   []uint64
+	}{
+		{&question.Filter{IsActive: true, Limit: 10}, []uint64{1, 3, 5}},
+		{&question.Filter{IsActive: false, Limit: 10}, []uint64{2, 4, 6}},
+		{&question.Filter{IsActive: true, Limit: 2}, []uint64{1, 3}},
+		{&question.Filter{IsActive: true, Limit: 10, Offset: 1}, []uint64{3, 5}},
+		{&question.Filter{IsActive: true, Limit: 10, IgnoreIds: []uint64{3}}, []uint64{1, 5}},
+		{&question.Filter{IsActive: true, Limit: 0}, []uint64{}},
+	}
+
+	for _, tt := range tests {
+		list, err := s.Filter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := ids(list.Questions); !equalIds(got, tt.want) {
+			t.Errorf("Filter(%v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	for _, filter := range []*question.Filter{{Limit: -1}, {Offset: -1}, {Limit: 1, PageToken: "bad"}} {
+		if _, err := s.Filter(filter); !errors.Is(err, question.ErrInvalidArgument) {
+			t.Errorf("Filter(%v) error = %v, want ErrInvalidArgument", filter, err)
+		}
+	}
+}
+
+func testFilterPages(t *testing.T, s question.Store) {
+	for i := 1; i <= 7; i++ {
+		put(t, s, question.Question{Text: "q", IsActive: i != 4})
+	}
+
+	var got []uint64
+	var pages int
+	filter := &question.Filter{IsActive: true, Limit: 2, IgnoreIds: []uint64{2}}
+
+	for {
+		list, err := s.Filter(filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pages++
+		got = append(got, ids(list.Questions)...)
+		if list.NextPageToken == "" {
+			break
+		}
+
+		filter.PageToken = list.NextPageToken
+	}
+
+	if want := []uint64{1, 3, 5, 6, 7}; !equalIds(got, want) || pages != 3 {
+		t.Errorf("pages returned %v in %d pages, want %v in 3", got, pages, want)
+	}
+
+	list, _ := s.Filter(&question.Filter{IsActive: true, Limit: 5, IgnoreIds: []uint64{2}})
+	if list.NextPageToken != "" {
+		t.Errorf("the last page has a next page token")
+	}
+}
+
+func testFilterValues(t *testing.T, s question.Store) {
+	put(t, s, question.Question{Text: "1", IsActive: true, IsGood: true, Category: "music", Tags: []string{"easy", "90s"}})
+	put(t, s, question.Question{Text: "2", IsActive: true, Category: "movies", Tags: []string{"easy"}})
+	put(t, s, question.Question{Text: "3", IsActive: true, IsGood: true, Tags: []string{"90s"}})
+	put(t, s, question.Question{Text: "4", IsActive: true})
+	put(t, s, question.Question{Text: "5", IsGood: true})
+
+	tests := []struct {
+		filter *question.Filter
+		want   []uint64
+	}{
+		{&question.Filter{Categories: []string{"music"}}, []uint64{1}},
+		{&question.Filter{Categories: []string{"music", "movies"}}, []uint64{1, 2}},
+		{&question.Filter{Categories: []string{""}}, []uint64{}},
+		{&question.Filter{Tags: []string{"90s"}}, []uint64{1, 3}},
+		{&question.Filter{Tags: []string{"easy", "90s"}}, []uint64{1, 2, 3}},
+		{&question.Filter{Tags: []string{"easy", "90s"}, AllTags: true}, []uint64{1}},
+		{&question.Filter{Tags: []string{"easy", ""}, AllTags: true}, []uint64{}},
+		{&question.Filter{Categories: []string{"movies"}, Tags: []string{"90s"}}, []uint64{}},
+		{&question.Filter{IsGood: wrapperspb.Bool(true)}, []uint64{1, 3}},
+		{&question.Filter{IsGood: wrapperspb.Bool(false)}, []uint64{2, 4}},
+		{&question.Filter{IsGood: wrapperspb.Bool(true), Tags: []string{"easy"}}, []uint64{1}},
+	}
+
+	for _, tt := range tests {
+		tt.filter.IsActive, tt.filter.Limit = true, 10
+
+		list, err := s.Filter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := ids(list.Questions); !equalIds(got, tt.want) {
+			t.Errorf("Filter(%v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	q, _ := s.Get(1)
+	q.Category, q.Tags = "movies", nil
+	put(t, s, *q)
+
+	list, _ := s.Filter(&question.Filter{IsActive: true, Limit: 10, Categories: []string{"music"}})
+	if len(list.Questions) != 0 {
+		t.Errorf("Filter matched a replaced category")
+	}
+
+	q, _ = s.Get(1)
+	q.IsGood = false
+	put(t, s, *q)
+
+	list, _ = s.Filter(&question.Filter{IsActive: true, Limit: 10, IsGood: wrapperspb.Bool(true)})
+	if got := ids(list.Questions); !equalIds(got, []uint64{3}) {
+		t.Errorf("Filter(good) after a question became bad = %v, want [3]", got)
+	}
+}
+
+func testRandom(t *testing.T, s question.Store) {
+	for i := 1; i <= 20; i++ {
+		put(t, s, question.Question{Text: "q", IsActive: true, IsGood: i <= 10})
+	}
+
+	list, err := s.Random(&question.RandomRequest{Count: 5, IsActive: true, IsGood: true, IgnoreIds: []uint64{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list.Questions) != 5 {
+		t.Errorf("Random returned %d questions, want 5", len(list.Questions))
+	}
+
+	seen := make(map[uint64]bool)
+	for _, q := range list.Questions {
+		if q.Id <= 2 || q.Id > 10 || seen[q.Id] {
+			t.Errorf("Random returned unexpected or repeated question %d", q.Id)
+		}
+		seen[q.Id] = true
+	}
+
+	list, _ = s.Random(&question.RandomRequest{Count: 50, IsActive: true, IsGood: false})
+	if len(list.Questions) != 10 {
+		t.Errorf("Random returned %d questions, want all 10", len(list.Questions))
+	}
+
+	if list, _ := s.Random(&question.RandomRequest{Count: 5}); len(list.Questions) != 0 {
+		t.Errorf("Random returned questions from an empty pool")
+	}
+
+	if _, err := s.Random(&question.RandomRequest{Count: -1}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Random(-1) error = %v, want ErrInvalidArgument", err)
+	}
+
+	hits := make(map[uint64]int)
+	for i := 0; i < 2000; i++ {
+		list, _ := s.Random(&question.RandomRequest{Count: 1, IsActive: true, IsGood: true})
+		hits[list.Questions[0].Id]++
+	}
+
+	for id := uint64(1); id <= 10; id++ {
+		if hits[id] < 100 || hits[id] > 320 {
+			t.Errorf("question %d was picked %d times out of 2000, the pick isn't uniform", id, hits[id])
+		}
+	}
+}
+
+func testForEach(t *testing.T, s question.Store) {
+	for _, id := range []uint64{5, 2, 9} {
+		put(t, s, question.Question{Id: id, Text: "q"})
+	}
+
+	var got []uint64
+	err := s.ForEach(func(q *question.Question) error {
+		got = append(got, q.Id)
+		return nil
+	})
+
+	if err != nil || !equalIds(got, []uint64{2, 5, 9}) {
+		t.Errorf("ForEach visited %v, %v, want 2, 5, 9", got, err)
+	}
+
+	stop := errors.New("stop")
+	var visited int
+	err = s.ForEach(func(q *question.Question) error {
+		visited++
+		return stop
+	})
+
+	if err != stop || visited != 1 {
+		t.Errorf("ForEach didn't stop on error: %v after %d questions", err, visited)
+	}
+}
+
+func testSnapshot(t *testing.T, s question.Store) {
+	snapshotter, ok := s.(question.Snapshotter)
+	if !ok {
+		t.Skip("the store doesn't support snapshots")
+	}
+
+	for _, id := range []uint64{5, 2, 9} {
+		put(t, s, question.Question{Id: id, Text: "q"})
+	}
+
+	var got []uint64
+	seq, err := snapshotter.Snapshot(func(q *question.Question) error {
+		got = append(got, q.Id)
+		return nil
+	})
+
+	if err != nil || !equalIds(got, []uint64{2, 5, 9}) || seq != 9 {
+		t.Errorf("Snapshot visited %v with sequence %d, %v, want 2, 5, 9 with sequence 9", got, seq, err)
+	}
+}
+
+func testCount(t *testing.T, s question.Store) {
+	put(t, s, question.Question{Text: "a", IsActive: true})
+	put(t, s, question.Question{Text: "b", IsActive: true, IsGood: true})
+	q := put(t, s, question.Question{Text: "c"})
+
+	counts, err := s.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counts.Active != 2 || counts.Inactive != 1 {
+		t.Errorf("Count = %+v, want 2 active and 1 inactive", counts)
+	}
+
+	q.IsActive = true
+	put(t, s, *q)
+
+	if counts, _ := s.Count(); counts.Active != 3 || counts.Inactive != 0 {
+		t.Errorf("Count after activation = %+v, want 3 active", counts)
+	}
+}
+
+func testLoad(t *testing.T, s question.Store) {
+	put(t, s, question.Question{Text: "old", IsActive: true, Tags: []string{"x"}})
+
+	loaded := []question.Question{
+		{Id: 1, Text: "replaced", IsActive: false, Version: 7, Tags: []string{"y"}},
+		{Id: 8, Text: "eight", IsActive: true, Version: 3},
+	}
+	if err := s.Load(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if q, _ := s.Get(1); q.Text != "replaced" || q.Version != 7 || len(q.Tags) != 1 || q.Tags[0] != "y" {
+		t.Errorf("Get after Load = %v, want the loaded question as it is", q)
+	}
+
+	list, _ := s.Filter(&question.Filter{IsActive: true, Limit: 10, Tags: []string{"x"}})
+	if len(list.Questions) != 0 {
+		t.Errorf("Load kept the indexes of a replaced question")
+	}
+
+	if seq, err := s.Sequence(); err != nil || seq != 8 {
+		t.Errorf("Sequence after Load = %d, %v, want 8", seq, err)
+	}
+
+	if err := s.SetSequence(20); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetSequence(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if q := put(t, s, question.Question{Text: "next"}); q.Id != 21 {
+		t.Errorf("id after SetSequence = %d, want 21", q.Id)
+	}
+
+	if err := s.Load([]question.Question{{Text: "no id"}}); !errors.Is(err, question.ErrInvalidArgument) {
+		t.Errorf("Load(no id) error = %v, want ErrInvalidArgument", err)
+	}
+
+	if counts, _ := s.Count(); counts.Active != 1 || counts.Inactive != 2 {
+		t.Errorf("Count after Load = %+v, want 1 active and 2 inactive", counts)
+	}
+}
@@ -0,0 +1,113 @@
+package question
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+	"golang.org/x/text/unicode/norm"
+)
+
+// splitWords calls fn for every word of the text. Words are sequences
+// of letters and digits, prefix reports whether the word is followed by *
+func splitWords(text string, fn func(word string, prefix bool)) {
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && isWordRune(runes[i]) {
+			i++
+		}
+
+		fn(string(runes[start:i]), i < len(runes) && runes[i] == '*')
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r)
+}
+
+// fold lowercases a word and removes diacritics, so "Café" and "cafe"
+// are the same word. Cyrillic letters keep their marks except ё,
+// because й is a separate letter which the Russian stemmer relies on
+func fold(word string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(word) {
+		switch {
+		case r == 'ё':
+			b.WriteRune('е')
+		case unicode.Is(unicode.Cyrillic, r):
+			b.WriteRune(r)
+		default:
+			for _, d := range norm.NFD.String(string(r)) {
+				if !unicode.Is(unicode.Mn, d) {
+					b.WriteRune(d)
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// stem reduces a folded word to its stem with the Russian stemmer
+// for Cyrillic words and the English one for Latin words
+func stem(word string) string {
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return russian.Stem(word, true)
+		case unicode.Is(unicode.Latin, r):
+			return english.Stem(word, true)
+		}
+	}
+
+	return word
+}
+
+// terms returns index terms of the text in order of appearance
+func terms(text string) []string {
+	var result []string
+
+	splitWords(text, func(word string, prefix bool) {
+		if term := stem(fold(word)); term != "" {
+			result = append(result, term)
+		}
+	})
+
+	return result
+}
+
+// queryTerm is a term of a search query. A prefix term matches
+// every index term starting with it, it is folded but not stemmed
+type queryTerm struct {
+	term   string
+	prefix bool
+}
+
+// parseQuery returns unique terms of a search query, "volc*" is a prefix term
+func parseQuery(query string) []queryTerm {
+	var result []queryTerm
+	seen := make(map[queryTerm]bool)
+
+	splitWords(query, func(word string, prefix bool) {
+		qt := queryTerm{term: fold(word), prefix: prefix}
+		if !prefix {
+			qt.term = stem(qt.term)
+		}
+
+		if qt.term != "" && !seen[qt] {
+			seen[qt] = true
+			result = append(result, qt)
+		}
+	})
+
+	return result
+}
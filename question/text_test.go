@@ -0,0 +1,59 @@
+package question
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Volcanoes erupted!", []string{"volcano", "erupt"}},
+		{"Running runs", []string{"run", "run"}},
+		{"Café au lait", []string{"cafe", "au", "lait"}},
+		{"Вулканы извергаются", []string{"вулка", "изверга"}},
+		{"Ёлки и ёжики", []string{"елк", "и", "ежик"}},
+		{"Москва, 1147", []string{"москв", "1147"}},
+		{"!?", nil},
+	}
+
+	for _, test := range tests {
+		if got := terms(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("terms(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestTermsMatchWordForms(t *testing.T) {
+	forms := [][]string{
+		{"volcano", "volcanoes", "Volcanos"},
+		{"capital", "capitals"},
+		{"столица", "столицы", "столицей", "столицу"},
+		{"город", "города", "городов", "городами"},
+		{"ёж", "еж", "ежи"},
+	}
+
+	for _, words := range forms {
+		want := terms(words[0])
+		for _, word := range words[1:] {
+			if got := terms(word); !reflect.DeepEqual(got, want) {
+				t.Errorf("terms(%q) = %q, want %q like %q", word, got, want, words[0])
+			}
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	got := parseQuery("Volcanoes volc* вулк* volcano")
+	want := []queryTerm{
+		{term: "volcano"},
+		{term: "volc", prefix: true},
+		{term: "вулк", prefix: true},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseQuery() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+package question
+
+import (
+	"encoding/base64"
+
+	"github.com/almostmoore/gbquestion/utils"
+)
+
+// encodePageToken returns an opaque page token pointing after the given key
+func encodePageToken(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// decodePageToken returns a key encoded into the page token
+func decodePageToken(token string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(key) != len(utils.Uinttob(0)) {
+		return nil, invalidArgument("page token %q is malformed", token)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,316 @@
+package question
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// trashBucketName is a bucket which maps IDs of deleted questions
+// to the questions with the time they were deleted
+var trashBucketName = []byte("trash")
+
+// Trasher is implemented by stores which move deleted questions into the trash
+type Trasher interface {
+	// Trash returns deleted questions in order of IDs
+	Trash() ([]*TrashedQuestion, error)
+
+	// Restore moves a question from the trash back
+	Restore(id uint64) (*Change, error)
+
+	// Purge removes questions from the trash for good and returns their number
+	Purge(req *PurgeRequest) (uint64, error)
+}
+
+var (
+	_ Trasher = (*Storage)(nil)
+	_ Trasher = (*MemoryStore)(nil)
+	_ Trasher = (*SQLiteStore)(nil)
+)
+
+// trashPurgerActor is recorded as the author of automatic purges
+var trashPurgerActor = Actor{Name: "trash-purger", Method: "Purge"}
+
+// trashAdd moves a deleted question into the trash as deleted by the actor
+func trashAdd(tx *bolt.Tx, q *Question, actor Actor) error {
+	b, err := tx.CreateBucketIfNotExists(trashBucketName)
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(&TrashedQuestion{
+		Question:  q,
+		DeletedAt: timestamppb.Now(),
+		DeletedBy: actor.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.Put(utils.Uinttob(q.Id), data)
+}
+
+// trashGet returns a question from the trash or nil if it isn't there
+func trashGet(tx *bolt.Tx, id uint64) (*TrashedQuestion, error) {
+	b := tx.Bucket(trashBucketName)
+	if b == nil {
+		return nil, nil
+	}
+
+	data := b.Get(utils.Uinttob(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	trashed := &TrashedQuestion{}
+	if err := proto.Unmarshal(data, trashed); err != nil {
+		return nil, err
+	}
+
+	return trashed, nil
+}
+
+// trashRemove removes a question from the trash and returns it.
+// It returns nil if the question isn't there
+func trashRemove(tx *bolt.Tx, id uint64) (*TrashedQuestion, error) {
+	trashed, err := trashGet(tx, id)
+	if err != nil || trashed == nil {
+		return nil, err
+	}
+
+	return trashed, tx.Bucket(trashBucketName).Delete(utils.Uinttob(id))
+}
+
+// Trash returns deleted questions in order of IDs
+func (qs *Storage) Trash() ([]*TrashedQuestion, error) {
+	var result []*TrashedQuestion
+
+	err := qs.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(trashBucketName)
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			trashed := &TrashedQuestion{}
+			if err := proto.Unmarshal(v, trashed); err != nil {
+				return err
+			}
+
+			result = append(result, trashed)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Restore moves a question from the trash back as its next version
+func (qs *Storage) Restore(id uint64) (*Change, error) {
+	var change *Change
+
+	err := qs.db.Batch(func(tx *bolt.Tx) error {
+		trashed, err := trashGet(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if trashed == nil {
+			return ErrNotFound
+		}
+
+		b, err := tx.CreateBucketIfNotExists(questionsBucketName)
+		if err != nil {
+			return err
+		}
+
+		q := *trashed.Question
+		q.Version = 0
+
+		change, err = qs.putQuestion(tx, b, q)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// purgeBefore validates a purge request and returns the time
+// to purge questions deleted before, it is zero if req.Id is set
+func purgeBefore(req *PurgeRequest) (time.Time, error) {
+	if req.Id == 0 && req.DeletedBefore == nil {
+		return time.Time{}, invalidArgument("ID or time to purge questions deleted before must be set")
+	}
+
+	if req.Id != 0 || req.DeletedBefore == nil {
+		return time.Time{}, nil
+	}
+
+	if err := req.DeletedBefore.CheckValid(); err != nil {
+		return time.Time{}, invalidArgument("invalid time to purge questions deleted before: %v", err)
+	}
+
+	return req.DeletedBefore.AsTime(), nil
+}
+
+// Purge removes a question with req.Id or, without an ID, all questions deleted
+// before req.DeletedBefore from the trash for good along with their history.
+// Their texts are removed from the audit log too, see auditForget
+func (qs *Storage) Purge(req *PurgeRequest) (uint64, error) {
+	before, err := purgeBefore(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged uint64
+
+	err = qs.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(trashBucketName)
+		if b == nil {
+			if req.Id != 0 {
+				return ErrNotFound
+			}
+			return nil
+		}
+
+		var questions []*Question
+		if req.Id != 0 {
+			trashed, err := trashGet(tx, req.Id)
+			if err != nil {
+				return err
+			}
+
+			if trashed == nil {
+				return ErrNotFound
+			}
+			questions = append(questions, trashed.Question)
+		} else {
+			err := b.ForEach(func(k, v []byte) error {
+				trashed := &TrashedQuestion{}
+				if err := proto.Unmarshal(v, trashed); err != nil {
+					return err
+				}
+
+				if trashed.DeletedAt.AsTime().Before(before) {
+					questions = append(questions, trashed.Question)
+				}
+				return nil
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		history := tx.Bucket(historyBucketName)
+		for _, q := range questions {
+			key := utils.Uinttob(q.Id)
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+
+			if history != nil && history.Bucket(key) != nil {
+				if err := history.DeleteBucket(key); err != nil {
+					return err
+				}
+			}
+
+			if err := auditForget(tx, q.Id); err != nil {
+				return err
+			}
+
+			if err := auditPurge(tx, qs.actor, q.Id); err != nil {
+				return err
+			}
+		}
+
+		purged = uint64(len(questions))
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// TrashPurger removes questions deleted more than retention ago
+// from the trash once an interval
+type TrashPurger struct {
+	trasher   Trasher
+	retention time.Duration
+	interval  time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewTrashPurger creates a purger, it does nothing until it is started.
+// Stores which record who changes questions record purges as made by trash-purger
+func NewTrashPurger(t Trasher, retention, interval time.Duration) *TrashPurger {
+	if as, ok := t.(ActorStore); ok {
+		if trasher, ok := as.As(trashPurgerActor).(Trasher); ok {
+			t = trasher
+		}
+	}
+
+	return &TrashPurger{
+		trasher:   t,
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start purges the trash right away and then every interval in background
+func (p *TrashPurger) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.run()
+
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the purger and waits for a running purge
+func (p *TrashPurger) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// run purges old questions, logging the outcome
+func (p *TrashPurger) run() {
+	n, err := p.trasher.Purge(&PurgeRequest{DeletedBefore: timestamppb.New(time.Now().Add(-p.retention))})
+	if err != nil {
+		log.Printf("Couldn't purge the trash: %v", err)
+		return
+	}
+
+	if n > 0 {
+		log.Printf("Purged %d questions deleted more than %s ago", n, p.retention)
+	}
+}
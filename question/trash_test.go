@@ -0,0 +1,92 @@
+package question_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/almostmoore/gbquestion/question"
+)
+
+func TestPurgeForgetsQuestion(t *testing.T) {
+	s := newBoltStorage(t)
+	editor := s.As(question.Actor{Name: "editor", Method: "Put"})
+
+	change, err := editor.Put(question.Question{Text: "secret text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := change.After.Id
+
+	if _, err := editor.Update(&question.Question{Id: id, Text: "another secret"}, []string{"text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := editor.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := s.As(question.Actor{Name: "admin", Method: "Purge"}).(question.Trasher)
+	if _, err := admin.Purge(&question.PurgeRequest{Id: id}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.History(id); !errors.Is(err, question.ErrNotFound) {
+		t.Errorf("History(purged) error = %v, want ErrNotFound", err)
+	}
+
+	log, err := s.AuditLog(&question.AuditRequest{QuestionId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(log.Entries) != 4 {
+		t.Fatalf("AuditLog() returned %d entries, want 4", len(log.Entries))
+	}
+
+	for _, entry := range log.Entries {
+		if entry.QuestionId != id || entry.Caller == "" {
+			t.Errorf("entry %v lost who changed question %d", entry, id)
+		}
+
+		for _, q := range []*question.Question{entry.Before, entry.After} {
+			if q.GetText() != "" {
+				t.Errorf("entry %v keeps a text of the purged question", entry)
+			}
+		}
+	}
+
+	if last := log.Entries[3]; last.Caller != "admin" || last.Method != "Purge" {
+		t.Errorf("the last entry = %v, want a purge by admin", last)
+	}
+}
+
+func TestTrashPurgerActor(t *testing.T) {
+	s := newBoltStorage(t)
+
+	change, err := s.Put(question.Question{Text: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Delete(change.After.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	purger := question.NewTrashPurger(s, 0, time.Hour)
+	purger.Start()
+	purger.Stop()
+
+	if trash, err := s.Trash(); err != nil || len(trash) != 0 {
+		t.Fatalf("Trash() after the purger = %v, %v, want none", trash, err)
+	}
+
+	log, err := s.AuditLog(&question.AuditRequest{QuestionId: change.After.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last := log.Entries[len(log.Entries)-1]; last.Caller != "trash-purger" || last.Method != "Purge" {
+		t.Errorf("the purge was recorded as %q calling %q, want trash-purger calling Purge", last.Caller, last.Method)
+	}
+}
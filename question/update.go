@@ -0,0 +1,84 @@
+package question
+
+import (
+	"github.com/almostmoore/gbquestion/utils"
+	"github.com/boltdb/bolt"
+	"github.com/golang/protobuf/proto"
+)
+
+// updaters copy a field named by a field mask path from src to dst
+var updaters = map[string]func(dst, src *Question){
+	"text":     func(dst, src *Question) { dst.Text = src.Text },
+	"isGood":   func(dst, src *Question) { dst.IsGood = src.IsGood },
+	"isActive": func(dst, src *Question) { dst.IsActive = src.IsActive },
+	"category": func(dst, src *Question) { dst.Category = src.Category },
+	"tags":     func(dst, src *Question) { dst.Tags = src.Tags },
+}
+
+// Update changes only the fields of a stored question listed in paths.
+// The stored question is read and written in the same transaction.
+// A non-zero version of q must match the stored one
+func (qs *Storage) Update(q *Question, paths []string) (*Change, error) {
+	if err := validatePaths(paths); err != nil {
+		return nil, err
+	}
+
+	var change *Change
+
+	err := qs.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(questionsBucketName)
+		if b == nil {
+			return ErrNotFound
+		}
+
+		data := b.Get(utils.Uinttob(q.Id))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		merged := Question{}
+		if err := proto.Unmarshal(data, &merged); err != nil {
+			return err
+		}
+
+		if err := applyPaths(&merged, q, paths); err != nil {
+			return err
+		}
+
+		var err error
+		change, err = qs.putQuestion(tx, b, merged)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// validatePaths checks that all fields of a field mask can be updated
+func validatePaths(paths []string) error {
+	if len(paths) == 0 {
+		return invalidArgument("update mask must not be empty")
+	}
+
+	for _, path := range paths {
+		if _, ok := updaters[path]; !ok {
+			return invalidArgument("field %q can't be updated", path)
+		}
+	}
+
+	return nil
+}
+
+// applyPaths copies the fields listed in paths and the expected version
+// from src to a stored question and validates the result
+func applyPaths(stored, src *Question, paths []string) error {
+	for _, path := range paths {
+		updaters[path](stored, src)
+	}
+
+	stored.Version = src.Version
+	return validate(stored)
+}
@@ -0,0 +1,103 @@
+package question
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// watchHistorySize is a number of the latest events kept for resuming
+	watchHistorySize = 1024
+
+	// watchBufferSize is a number of events which may wait for a slow subscriber
+	watchBufferSize = 64
+)
+
+// Watcher assigns revisions to question changes and fans them out to subscribers.
+// Revisions aren't persisted: they continue from the time the watcher is created
+// in nanoseconds, so revisions of a previous run of the server are older
+// than the kept events and resuming from them fails instead of skipping changes
+type Watcher struct {
+	mu       sync.Mutex
+	revision uint64
+	history  []*ChangeEvent
+	subs     map[*subscription]struct{}
+}
+
+// subscription is a channel of events of one subscriber.
+// The channel is closed when the subscriber can't keep up with the events
+type subscription struct {
+	events chan *ChangeEvent
+}
+
+// NewWatcher creates a new watcher
+func NewWatcher() *Watcher {
+	return &Watcher{
+		revision: uint64(time.Now().UnixNano()),
+		subs:     make(map[*subscription]struct{}),
+	}
+}
+
+// Publish sends a change to all subscribers
+func (w *Watcher) Publish(change *Change) {
+	e := &ChangeEvent{}
+	switch {
+	case change.Before == nil:
+		e.Type, e.Question = ChangeEvent_CREATED, change.After
+	case change.After == nil:
+		e.Type, e.Question = ChangeEvent_DELETED, change.Before
+	default:
+		e.Type, e.Question = ChangeEvent_UPDATED, change.After
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.revision++
+	e.Revision = w.revision
+
+	w.history = append(w.history, e)
+	if len(w.history) > watchHistorySize {
+		w.history = w.history[len(w.history)-watchHistorySize:]
+	}
+
+	for sub := range w.subs {
+		select {
+		case sub.events <- e:
+		default:
+			close(sub.events)
+			delete(w.subs, sub)
+		}
+	}
+}
+
+// Subscribe returns events which happened after the given revision and
+// a channel of the further ones. Zero revision means only new events.
+// The returned func must be called to unsubscribe
+func (w *Watcher) Subscribe(fromRevision uint64) ([]*ChangeEvent, <-chan *ChangeEvent, func(), error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var backlog []*ChangeEvent
+	if fromRevision > 0 {
+		oldest := w.revision - uint64(len(w.history))
+		if fromRevision < oldest || fromRevision > w.revision {
+			return nil, nil, nil, ErrRevisionUnavailable
+		}
+
+		backlog = append(backlog, w.history[fromRevision-oldest:]...)
+	}
+
+	sub := &subscription{
+		events: make(chan *ChangeEvent, watchBufferSize),
+	}
+	w.subs[sub] = struct{}{}
+
+	cancel := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs, sub)
+	}
+
+	return backlog, sub.events, cancel, nil
+}